@@ -0,0 +1,198 @@
+package bore
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/uptrace/bun"
+	"go.trulyao.dev/bore/v2/database/models"
+	"go.trulyao.dev/bore/v2/pkg/blobstore"
+	"go.trulyao.dev/bore/v2/pkg/errs"
+	"go.trulyao.dev/bore/v2/pkg/lib"
+	"go.trulyao.dev/bore/v2/pkg/mimetype"
+)
+
+// blobGracePeriod keeps blobs written by a copy that has not committed its item yet from being collected.
+const blobGracePeriod = time.Hour
+
+// itemContent is the content of a new item, either held in memory or already written to the blob store.
+type itemContent struct {
+	data     []byte
+	blobHash string
+	size     int64
+}
+
+func (c itemContent) hash() string {
+	if c.blobHash != "" {
+		return c.blobHash
+	}
+
+	return lib.ComputeChecksum(c.data)
+}
+
+// stored returns the content as it is stored in the contents table, see events.Event.Content.
+func (c itemContent) stored() *models.Content {
+	if c.blobHash != "" {
+		return &models.Content{Hash: c.blobHash, BlobHash: c.blobHash, Size: c.size}
+	}
+
+	return &models.Content{Hash: c.hash(), Content: c.data, Size: int64(len(c.data))}
+}
+
+// SetFrom copies the content of the reader, see Set.
+// Content above Config.BlobThresholdBytes is streamed into the blob store instead of being held in memory, unless the data is encrypted.
+// Such content can not be appended to an item, and its mimetype is detected from the part below the threshold.
+func (i *clipboardNamespace) SetFrom(ctx context.Context, r io.Reader, opts SetClipboardOptions) (SetResult, error) {
+	threshold := i.config.BlobThresholdBytes()
+	if threshold <= 0 || i.repository.Cipher().Enabled() {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return SetResult{}, errs.Wrap(err, "failed to read content")
+		}
+
+		return i.Set(ctx, data, opts)
+	}
+
+	head, err := io.ReadAll(io.LimitReader(r, threshold+1))
+	if err != nil {
+		return SetResult{}, errs.Wrap(err, "failed to read content")
+	}
+
+	if int64(len(head)) <= threshold {
+		return i.Set(ctx, head, opts)
+	}
+
+	if opts.Append {
+		return SetResult{}, errs.ErrItemInBlobStore
+	}
+
+	collectionName, err := i.resolveCollection(ctx, &opts)
+	if err != nil {
+		return SetResult{}, err
+	}
+
+	// Secrets are rarely buried deep in large content, the detector only scans the start of its input anyway.
+	secret, err := i.detectSecret(head, opts.CollectionID, collectionName)
+	if err != nil {
+		return SetResult{}, err
+	}
+
+	if secret != nil {
+		opts.Sensitive = opts.Sensitive || secret.Sensitive
+	}
+
+	if opts.Mimetype == "" {
+		opts.Mimetype = mimetype.Detect(head, opts.Filename)
+	}
+
+	hash, size, err := i.blobs.Put(io.MultiReader(bytes.NewReader(head), r))
+	if err != nil {
+		return SetResult{}, errs.Wrap(err, "failed to store content")
+	}
+
+	if err := i.create(ctx, itemContent{data: []byte{}, blobHash: hash, size: size}, opts); err != nil {
+		return SetResult{}, err
+	}
+
+	return SetResult{Secret: secret}, nil
+}
+
+// OpenContent returns a reader for the content of the item wherever it is stored, the caller must close it.
+func (i *clipboardNamespace) OpenContent(item *models.Item) (io.ReadCloser, error) {
+	if !item.InBlobStore() {
+		return io.NopCloser(bytes.NewReader(item.Content)), nil
+	}
+
+	file, err := i.blobs.Open(item.BlobHash)
+	if err != nil {
+		return nil, errs.Wrap(err, "failed to open the content of item "+item.ID)
+	}
+
+	return file, nil
+}
+
+// deliver writes the content of the item to w if set, and returns it (read from the blob store if needed) otherwise.
+func (i *clipboardNamespace) deliver(item *models.Item, w io.Writer) ([]byte, error) {
+	if w == nil && !item.InBlobStore() {
+		return item.Content, nil
+	}
+
+	reader, err := i.OpenContent(item)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = reader.Close() }()
+
+	if w == nil {
+		return io.ReadAll(reader)
+	}
+
+	_, err = io.Copy(w, reader)
+	return nil, err
+}
+
+// forwardToSystemClipboard writes new content to the system clipboard if passthrough is enabled.
+// Content in the blob store is only forwarded when the copy asks for it, reading it back into memory is what storing it on disk avoids.
+func (i *clipboardNamespace) forwardToSystemClipboard(
+	ctx context.Context,
+	content itemContent,
+	opts SetClipboardOptions,
+) error {
+	forward := opts.Passthrough || (i.config.ClipboardPassthrough && content.blobHash == "")
+	if !i.clipboard.Available() || !forward {
+		return nil
+	}
+
+	data := content.data
+	if content.blobHash != "" {
+		var err error
+		if data, err = i.blobs.ReadAll(content.blobHash); err != nil {
+			return errs.Wrap(err, "failed to read content from the blob store")
+		}
+	}
+
+	return i.clipboard.Write(ctx, data)
+}
+
+// collectContents deletes contents that are no longer referenced, then removes the blobs none of the remaining contents are kept in if they were written before the cutoff.
+// The given blob hashes are removed regardless of their age as long as nothing references them, so purged content does not linger on disk.
+func (i *clipboardNamespace) collectContents(ctx context.Context, before time.Time, hashes ...string) (int, error) {
+	err := i.db.RunInTx(ctx, &sql.TxOptions{Isolation: 0, ReadOnly: false}, func(ctx context.Context, tx bun.Tx) error {
+		_, err := i.repository.Contents().DeleteUnreferenced(ctx, tx)
+		return err
+	})
+	if err != nil {
+		return 0, errs.Wrap(err, "failed to delete unreferenced contents")
+	}
+
+	referenced, err := i.repository.Contents().FindBlobs(ctx)
+	if err != nil {
+		return 0, errs.Wrap(err, "failed to find referenced blobs")
+	}
+
+	removed := 0
+	for _, hash := range hashes {
+		if _, ok := referenced[hash]; ok || hash == "" {
+			continue
+		}
+
+		if err := i.blobs.Remove(hash); errors.Is(err, blobstore.ErrBlobNotFound) {
+			continue
+		} else if err != nil {
+			return removed, errs.Wrap(err, "failed to remove blob "+hash)
+		}
+
+		removed++
+	}
+
+	collected, err := i.blobs.Collect(referenced, before)
+	if err != nil {
+		return removed + collected, errs.Wrap(err, "failed to collect unreferenced blobs")
+	}
+
+	return removed + collected, nil
+}
@@ -1,16 +1,20 @@
 package bore
 
 import (
+	"context"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 
 	"github.com/uptrace/bun"
 	"go.trulyao.dev/bore/v2/database"
 	"go.trulyao.dev/bore/v2/database/repository"
+	"go.trulyao.dev/bore/v2/pkg/blobstore"
 	"go.trulyao.dev/bore/v2/pkg/clipboard"
 	"go.trulyao.dev/bore/v2/pkg/errs"
 	"go.trulyao.dev/bore/v2/pkg/events"
+	"go.trulyao.dev/bore/v2/pkg/secrets"
 )
 
 type Bore struct {
@@ -31,9 +35,17 @@ type Bore struct {
 	// repository is the interface for accessing database operations
 	repository repository.Repository
 
+	// detector flags copied content that looks like a secret
+	detector *secrets.Detector
+
+	// blobs keeps contents above the blob threshold as files in the data directory
+	blobs *blobstore.Store
+
 	// MARK: Namespaces
 	items       *clipboardNamespace
 	collections *collectionNamespace
+	trash       *trashNamespace
+	keys        *keyNamespace
 }
 
 // New creates a new Bore instance with the provided configuration.
@@ -46,6 +58,11 @@ func New(config *Config) (*Bore, error) {
 		return nil, errs.ErrStoragePathRequired
 	}
 
+	detector, err := config.SecretDetection.Detector()
+	if err != nil {
+		return nil, errs.Wrap(err, "invalid secret detection configuration")
+	}
+
 	if err := os.MkdirAll(config.DataDir, 0o755); err != nil {
 		return nil, errs.Wrap(err, "failed to create data directory")
 	}
@@ -55,8 +72,14 @@ func New(config *Config) (*Bore, error) {
 		return nil, errs.ErrFailedToConnectToDB.WithError(err)
 	}
 
+	cipher, err := loadCipher(context.Background(), conn, config.Encryption)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
 	clipboard, _ := clipboard.NewNativeClipboard()
-	repository := repository.NewRepository(conn)
+	repository := repository.NewRepository(conn, cipher)
 
 	return &Bore{
 		db:         conn,
@@ -64,6 +87,8 @@ func New(config *Config) (*Bore, error) {
 		clipboard:  clipboard,
 		repository: repository,
 		manager:    events.NewManager(conn, repository),
+		detector:   detector,
+		blobs:      blobstore.New(filepath.Join(config.DataDir, "blobs")),
 	}, nil
 }
 
@@ -130,6 +155,28 @@ func (b *Bore) Collections() *collectionNamespace {
 	return b.collections
 }
 
+// Trash returns the trash namespace for restoring deleted items and collections.
+func (b *Bore) Trash() *trashNamespace {
+	b.withNamespaceLock(func() {
+		if b.trash == nil {
+			b.trash = &trashNamespace{b}
+		}
+	})
+
+	return b.trash
+}
+
+// Keys returns the keys namespace for encrypting the data at rest.
+func (b *Bore) Keys() *keyNamespace {
+	b.withNamespaceLock(func() {
+		if b.keys == nil {
+			b.keys = &keyNamespace{b}
+		}
+	})
+
+	return b.keys
+}
+
 func (b *Bore) Close() error {
 	if err := b.db.Close(); err != nil {
 		return errs.ErrFailedToCloseDB.WithError(err)
@@ -0,0 +1,86 @@
+package bore_test
+
+import (
+	"context"
+	"testing"
+
+	"go.trulyao.dev/bore/v2"
+	"go.trulyao.dev/bore/v2/database/models"
+	"go.trulyao.dev/bore/v2/pkg/mimetype"
+)
+
+// newBore returns a Bore instance backed by a fresh data directory.
+func newBore(t *testing.T) *bore.Bore {
+	t.Helper()
+
+	return newBoreWithConfig(t, &bore.Config{})
+}
+
+// newBoreWithConfig is newBore with a custom configuration, the data directory is always a fresh one.
+func newBoreWithConfig(t *testing.T, config *bore.Config) *bore.Bore {
+	t.Helper()
+
+	config.DataDir = t.TempDir()
+	b, err := bore.New(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	t.Cleanup(func() { _ = b.Close() })
+	return b
+}
+
+// copyItem copies the content and returns the ID of the item holding it.
+func copyItem(t *testing.T, b *bore.Bore, content string, opts bore.SetClipboardOptions) string {
+	t.Helper()
+
+	if opts.Mimetype == "" {
+		opts.Mimetype = mimetype.MimeTypeTextPlain
+	}
+
+	ctx := context.Background()
+	if _, err := b.Clipboard().Set(ctx, []byte(content), opts); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	repository, err := b.Repository()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	item, err := repository.Items().FindLatest(ctx, opts.CollectionID)
+	if err != nil || item == nil {
+		t.Fatalf("expected the copied item to be found, got %+v (%v)", item, err)
+	}
+
+	return item.ID
+}
+
+// createCollection creates a collection and returns its ID.
+func createCollection(t *testing.T, b *bore.Bore, name string) string {
+	t.Helper()
+
+	result, err := b.Collections().Create(context.Background(), bore.CreateCollectionOptions{Name: name})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	return result.ID
+}
+
+// findItem returns the current item with the given ID, or nil if there is none.
+func findItem(t *testing.T, b *bore.Bore, itemID string) *models.Item {
+	t.Helper()
+
+	repository, err := b.Repository()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	item, err := repository.Items().FindById(context.Background(), itemID, "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	return item
+}
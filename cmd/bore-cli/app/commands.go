@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/urfave/cli/v2"
+	"go.trulyao.dev/bore/v2"
 	"go.trulyao.dev/bore/v2/cmd/bore-cli/app/handler"
 )
 
@@ -77,6 +78,21 @@ func (a *App) createRootCmd() *cli.App {
 			a.resetCommand(),
 			a.copyCommand(),
 			a.pasteCommand(),
+			a.historyCommand(),
+			a.searchCommand(),
+			a.uiCommand(),
+			a.pinCommand(),
+			a.unpinCommand(),
+			a.mvCommand(),
+			a.editCommand(),
+			a.itemCommand(),
+			a.forgetCommand(),
+			a.pruneCommand(),
+			a.undoCommand(),
+			a.redoCommand(),
+			a.trashCommand(),
+			a.keyCommand(),
+			a.clearClipboardCommand(),
 			a.collectionsCommand(),
 		},
 	}
@@ -132,13 +148,13 @@ func (a *App) copyCommand() *cli.Command {
 			&cli.StringFlag{
 				Name:    handler.FlagCollection,
 				Aliases: []string{"c"},
-				Usage:   "Collection ID to associate with the copied content",
+				Usage:   "Name or ID of the collection to associate with the copied content",
 			},
 			&cli.StringFlag{
 				Name:    handler.FlagMimeType,
 				Aliases: []string{"m"},
-				Usage:   "MIME type of the content being copied (e.g., text/plain, image/png)",
-				Value:   "text/plain",
+				Usage:   "MIME type of the content being copied, with optional parameters (e.g., text/plain, image/png, text/plain; charset=utf-8), or auto to detect it from the content",
+				Value:   handler.MimeTypeAuto,
 			},
 			&cli.StringFlag{
 				Name:    handler.FlagInputFile,
@@ -152,11 +168,40 @@ func (a *App) copyCommand() *cli.Command {
 				Usage:   "Copy content to the system clipboard ONLY",
 				Value:   false,
 			},
+			&cli.DurationFlag{
+				Name:  handler.FlagClearAfter,
+				Usage: "Clear the system clipboard after this long unless something else was copied, nothing is recorded in the history (used with --system)",
+			},
 			&cli.StringFlag{
 				Name:    handler.FlagFormat,
 				Aliases: []string{"f"},
 				Usage:   "Input format of the content being copied (text, base64)",
 			},
+			&cli.DurationFlag{
+				Name:  handler.FlagTTL,
+				Usage: "Delete the item automatically after this long (e.g. 10m, 24h)",
+			},
+			&cli.IntFlag{
+				Name:  handler.FlagMaxPastes,
+				Usage: "Delete the item automatically after it has been pasted this many times",
+			},
+			&cli.BoolFlag{
+				Name:  handler.FlagCreate,
+				Usage: "Create the collection passed with --collection if it does not exist",
+			},
+			&cli.BoolFlag{
+				Name:    handler.FlagAppend,
+				Aliases: []string{"a"},
+				Usage:   "Append to the latest item in the collection instead of creating a new item",
+			},
+			&cli.StringFlag{
+				Name:  handler.FlagSeparator,
+				Usage: "Separator inserted before the appended content (used with --append), escapes like \\n are supported",
+			},
+			&cli.BoolFlag{
+				Name:  handler.FlagSecret,
+				Usage: "Mark the copied content as sensitive, it is masked in listings and expires after the configured sensitive TTL",
+			},
 		},
 		Args:      true,
 		ArgsUsage: "[content]",
@@ -175,7 +220,7 @@ func (a *App) pasteCommand() *cli.Command {
 			&cli.StringFlag{
 				Name:    handler.FlagCollection,
 				Aliases: []string{"c"},
-				Usage:   "Collection ID to paste content from",
+				Usage:   "Name or ID of the collection to paste content from",
 			},
 			&cli.StringFlag{
 				Name:        handler.FlagFormat,
@@ -196,6 +241,16 @@ func (a *App) pasteCommand() *cli.Command {
 				Usage:   "Delete the content from the clipboard after pasting",
 				Value:   false,
 			},
+			&cli.BoolFlag{
+				Name:  handler.FlagForce,
+				Usage: "Delete the content after pasting even if it is pinned (used with --delete)",
+				Value: false,
+			},
+			&cli.BoolFlag{
+				Name:  handler.FlagPurge,
+				Usage: "Erase the content from the history instead of moving it to the trash, this cannot be undone (used with --delete)",
+				Value: false,
+			},
 			&cli.StringFlag{
 				Name:    handler.FlagOutputFile,
 				Aliases: []string{"o"},
@@ -205,9 +260,13 @@ func (a *App) pasteCommand() *cli.Command {
 			&cli.StringFlag{
 				Name:    handler.FlagIdentifier,
 				Aliases: []string{"id"},
-				Usage:   "Identifier of the specific clipboard entry to paste. If not provided, the most recent entry will be used.",
+				Usage:   "Identifier of the specific clipboard entry to paste, or a relative reference like @2 or work@2 for the third most recent entry. Deleted entries can be recovered by ID. If not provided, the most recent entry will be used.",
 				Value:   "",
 			},
+			&cli.BoolFlag{
+				Name:  handler.FlagReveal,
+				Usage: "Show the content of sensitive items in JSON output instead of masking it",
+			},
 		},
 		Action: func(ctx *cli.Context) error {
 			return a.handler.Paste(ctx)
@@ -215,6 +274,384 @@ func (a *App) pasteCommand() *cli.Command {
 	}
 }
 
+func (a *App) historyCommand() *cli.Command {
+	// nolint:exhaustruct
+	return &cli.Command{
+		Name:  "history",
+		Usage: "List clipboard items, most recent first",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    handler.FlagCollection,
+				Aliases: []string{"c"},
+				Usage:   "Name or ID of the collection to list items from. If not provided, items from all collections will be listed.",
+			},
+			&cli.StringFlag{
+				Name:    handler.FlagMimeType,
+				Aliases: []string{"m"},
+				Usage:   "Only list items with this MIME type, parameters like charset are ignored unless given (e.g. text/plain, image/*)",
+			},
+			&cli.StringFlag{
+				Name:  handler.FlagSince,
+				Usage: "Only list items created after this time (e.g. 24h, 2006-01-02)",
+			},
+			&cli.StringFlag{
+				Name:  handler.FlagUntil,
+				Usage: "Only list items created before this time (e.g. 1h, 2006-01-02)",
+			},
+			&cli.IntFlag{
+				Name:    handler.FlagLimit,
+				Aliases: []string{"n"},
+				Usage:   "Maximum number of items to list (0 for no limit)",
+				Value:   20,
+			},
+			&cli.Int64Flag{
+				Name:  handler.FlagCursor,
+				Usage: "Cursor returned by a previous page to continue listing from",
+			},
+			&cli.StringFlag{
+				Name:    handler.FlagFormat,
+				Aliases: []string{"f"},
+				Usage:   "Output format (text, json)",
+			},
+			&cli.BoolFlag{
+				Name:  handler.FlagReveal,
+				Usage: "Show previews of sensitive items instead of masking them",
+			},
+		},
+		Action: func(ctx *cli.Context) error {
+			return a.handler.History(ctx)
+		},
+	}
+}
+
+func (a *App) searchCommand() *cli.Command {
+	// nolint:exhaustruct
+	return &cli.Command{
+		Name:      "search",
+		Usage:     "Search clipboard items by content",
+		Args:      true,
+		ArgsUsage: "<query>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    handler.FlagCollection,
+				Aliases: []string{"c"},
+				Usage:   "Name or ID of the collection to search in. If not provided, all collections will be searched.",
+			},
+			&cli.BoolFlag{
+				Name:    handler.FlagRegex,
+				Aliases: []string{"r"},
+				Usage:   "Treat the query as a regular expression and only return exact pattern matches",
+				Value:   false,
+			},
+			&cli.IntFlag{
+				Name:    handler.FlagLimit,
+				Aliases: []string{"n"},
+				Usage:   "Maximum number of results (0 for no limit)",
+				Value:   20,
+			},
+			&cli.StringFlag{
+				Name:    handler.FlagFormat,
+				Aliases: []string{"f"},
+				Usage:   "Output format (text, json)",
+			},
+		},
+		Action: func(ctx *cli.Context) error {
+			return a.handler.Search(ctx)
+		},
+	}
+}
+
+func (a *App) uiCommand() *cli.Command {
+	// nolint:exhaustruct
+	return &cli.Command{
+		Name:  "ui",
+		Usage: "Browse the clipboard history interactively",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  handler.FlagReveal,
+				Usage: "Show the content of sensitive items instead of masking it",
+			},
+		},
+		Action: func(ctx *cli.Context) error {
+			return a.handler.UI(ctx)
+		},
+	}
+}
+
+func (a *App) pinCommand() *cli.Command {
+	// nolint:exhaustruct
+	return &cli.Command{
+		Name:      "pin",
+		Usage:     "Pin an item so it is listed first and protected from deletion",
+		Args:      true,
+		ArgsUsage: "[item id]",
+		Action: func(ctx *cli.Context) error {
+			return a.handler.PinItem(ctx)
+		},
+	}
+}
+
+func (a *App) mvCommand() *cli.Command {
+	// nolint:exhaustruct
+	return &cli.Command{
+		Name:      "mv",
+		Usage:     "Move an item to another collection, pass an empty collection to make it uncategorized",
+		Args:      true,
+		ArgsUsage: "[item id] [collection name or id]",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  handler.FlagCreate,
+				Usage: "Create the target collection if it does not exist",
+			},
+		},
+		Action: func(ctx *cli.Context) error {
+			return a.handler.MoveItem(ctx)
+		},
+	}
+}
+
+func (a *App) editCommand() *cli.Command {
+	// nolint:exhaustruct
+	return &cli.Command{
+		Name:      "edit",
+		Usage:     "Edit the content of an item in $VISUAL or $EDITOR, the latest item is used if none is given",
+		Args:      true,
+		ArgsUsage: "[item id or reference (@N, collection@N)]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    handler.FlagCollection,
+				Aliases: []string{"c"},
+				Usage:   "Name or ID of the collection to look up the item in",
+			},
+		},
+		Action: func(ctx *cli.Context) error {
+			return a.handler.EditItem(ctx)
+		},
+	}
+}
+
+func (a *App) itemCommand() *cli.Command {
+	// nolint:exhaustruct
+	return &cli.Command{
+		Name:  "item",
+		Usage: "Inspect clipboard items",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "timeline",
+				Usage:     "Show when an item was created, copied again and deleted, the latest item is used if none is given",
+				Args:      true,
+				ArgsUsage: "[item id or reference (@N, collection@N)]",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    handler.FlagCollection,
+						Aliases: []string{"c"},
+						Usage:   "Name or ID of the collection to look up the item in",
+					},
+					&cli.StringFlag{
+						Name:    handler.FlagFormat,
+						Aliases: []string{"f"},
+						Usage:   "Output format (text, json)",
+					},
+					&cli.BoolFlag{
+						Name:  handler.FlagReveal,
+						Usage: "Show the content of a sensitive item instead of masking it",
+					},
+				},
+				Action: func(ctx *cli.Context) error {
+					return a.handler.ItemTimeline(ctx)
+				},
+			},
+		},
+	}
+}
+
+func (a *App) forgetCommand() *cli.Command {
+	// nolint:exhaustruct
+	return &cli.Command{
+		Name:      "forget",
+		Usage:     "Permanently erase an item and its content from the trash, undo history and event log, deleted items included",
+		Args:      true,
+		ArgsUsage: "[item id or reference (@N, collection@N)]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    handler.FlagCollection,
+				Aliases: []string{"c"},
+				Usage:   "Name or ID of the collection to look up the item in",
+			},
+			&cli.BoolFlag{
+				Name:    handler.FlagForce,
+				Aliases: []string{"f"},
+				Usage:   "Forget the item without confirmation, even if it is pinned",
+				Value:   false,
+			},
+		},
+		Action: func(ctx *cli.Context) error {
+			return a.handler.ForgetItem(ctx)
+		},
+	}
+}
+
+func (a *App) undoCommand() *cli.Command {
+	// nolint:exhaustruct
+	return &cli.Command{
+		Name:  "undo",
+		Usage: "Undo the most recent operation (copy, delete, pin, move, edit, or a change to a collection)",
+		Action: func(ctx *cli.Context) error {
+			return a.handler.Undo(ctx)
+		},
+	}
+}
+
+func (a *App) redoCommand() *cli.Command {
+	// nolint:exhaustruct
+	return &cli.Command{
+		Name:  "redo",
+		Usage: "Redo the most recently undone operation",
+		Action: func(ctx *cli.Context) error {
+			return a.handler.Redo(ctx)
+		},
+	}
+}
+
+func (a *App) unpinCommand() *cli.Command {
+	// nolint:exhaustruct
+	return &cli.Command{
+		Name:      "unpin",
+		Usage:     "Remove the pin from an item",
+		Args:      true,
+		ArgsUsage: "[item id]",
+		Action: func(ctx *cli.Context) error {
+			return a.handler.UnpinItem(ctx)
+		},
+	}
+}
+
+func (a *App) pruneCommand() *cli.Command {
+	// nolint:exhaustruct
+	return &cli.Command{
+		Name:  "prune",
+		Usage: "Remove items that fall outside the configured retention policies",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  handler.FlagDryRun,
+				Usage: "Only report what would be removed",
+				Value: false,
+			},
+			&cli.StringFlag{
+				Name:    handler.FlagFormat,
+				Aliases: []string{"f"},
+				Usage:   "Output format (text, json)",
+			},
+		},
+		Action: func(ctx *cli.Context) error {
+			return a.handler.Prune(ctx)
+		},
+	}
+}
+
+func (a *App) trashCommand() *cli.Command {
+	// nolint:exhaustruct
+	return &cli.Command{
+		Name:  "trash",
+		Usage: "Manage deleted items and collections",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: "List deleted items and collections that can be restored",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    handler.FlagFormat,
+						Aliases: []string{"f"},
+						Usage:   "Output format (text, json)",
+					},
+					&cli.BoolFlag{
+						Name:  handler.FlagReveal,
+						Usage: "Show the content of sensitive items instead of masking it",
+					},
+				},
+				Action: func(ctx *cli.Context) error {
+					return a.handler.ListTrash(ctx)
+				},
+			},
+			{
+				Name:      "restore",
+				Usage:     "Restore a deleted item, or a deleted collection with its items",
+				ArgsUsage: "[item or collection id]",
+				Args:      true,
+				Action: func(ctx *cli.Context) error {
+					return a.handler.RestoreFromTrash(ctx)
+				},
+			},
+			{
+				Name:  "empty",
+				Usage: "Permanently remove everything in the trash",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:    handler.FlagForce,
+						Aliases: []string{"f"},
+						Usage:   "Empty the trash without confirmation",
+						Value:   false,
+					},
+				},
+				Action: func(ctx *cli.Context) error {
+					return a.handler.EmptyTrash(ctx)
+				},
+			},
+		},
+	}
+}
+
+func (a *App) clearClipboardCommand() *cli.Command {
+	// nolint:exhaustruct
+	return &cli.Command{
+		Name:   handler.CommandClearClipboard,
+		Usage:  "Clear the system clipboard for `copy --clear-after`, this is not meant to be run directly",
+		Hidden: true,
+		Flags: []cli.Flag{
+			&cli.DurationFlag{
+				Name: handler.FlagClearAfter,
+			},
+		},
+		Action: func(ctx *cli.Context) error {
+			return a.handler.ClearClipboard(ctx)
+		},
+	}
+}
+
+func (a *App) keyCommand() *cli.Command {
+	keyFileFlag := &cli.StringFlag{
+		Name:  handler.FlagKeyFile,
+		Usage: "Use the key in this file instead of a passphrase, a new key is generated if the file does not exist",
+	}
+
+	// nolint:exhaustruct
+	return &cli.Command{
+		Name:  "key",
+		Usage: "Manage the encryption of items and history at rest",
+		Description: "Without --key-file, the passphrase is read from $" + bore.DefaultPassphraseEnv +
+			" (or the variable set in encryption.passphrase_env) or prompted for.\n" +
+			"rotate reads the new passphrase from $" + handler.EnvNewPassphrase + " instead, since the configured variable still holds the current one.",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "init",
+				Usage: "Encrypt all existing and future items and history",
+				Flags: []cli.Flag{keyFileFlag},
+				Action: func(ctx *cli.Context) error {
+					return a.handler.InitKey(ctx)
+				},
+			},
+			{
+				Name:  "rotate",
+				Usage: "Re-encrypt all items and history with a new key",
+				Flags: []cli.Flag{keyFileFlag},
+				Action: func(ctx *cli.Context) error {
+					return a.handler.RotateKey(ctx)
+				},
+			},
+		},
+	}
+}
+
 func (a *App) collectionsCommand() *cli.Command {
 	// nolint:exhaustruct
 	return &cli.Command{
@@ -256,7 +693,7 @@ func (a *App) collectionsCommand() *cli.Command {
 				Name:      "delete",
 				Usage:     "Delete a collection",
 				Args:      true,
-				ArgsUsage: "[collection id]",
+				ArgsUsage: "[collection name or id]",
 				Flags: []cli.Flag{
 					&cli.BoolFlag{
 						Name:    handler.FlagForce,
@@ -264,6 +701,11 @@ func (a *App) collectionsCommand() *cli.Command {
 						Usage:   "Force deletion without confirmation",
 						Value:   false,
 					},
+					&cli.BoolFlag{
+						Name:  handler.FlagIncludePinned,
+						Usage: "Delete the collection even if it contains pinned items",
+						Value: false,
+					},
 				},
 				Action: func(ctx *cli.Context) error {
 					return a.handler.DeleteCollection(ctx)
@@ -272,7 +714,7 @@ func (a *App) collectionsCommand() *cli.Command {
 			{
 				Name:      "rename",
 				Usage:     "Rename a collection",
-				ArgsUsage: "[collection id] [new name]",
+				ArgsUsage: "[collection name or id] [new name]",
 				Args:      true,
 				Action: func(ctx *cli.Context) error {
 					return a.handler.RenameCollection(ctx)
@@ -287,7 +729,13 @@ func (a *App) collectionsCommand() *cli.Command {
 					{
 						Name:      "set",
 						Usage:     "Set the default collection",
-						ArgsUsage: "[collection id]",
+						ArgsUsage: "[collection name or id]",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  handler.FlagCreate,
+								Usage: "Create the collection if it does not exist",
+							},
+						},
 						Action: func(ctx *cli.Context) error {
 							return a.handler.SetDefaultCollection(ctx)
 						},
@@ -1,10 +1,12 @@
 package config
 
 import (
+	"context"
 	"os"
 	"path"
 
 	"go.trulyao.dev/bore/v2"
+	"go.trulyao.dev/bore/v2/database/models"
 	"go.trulyao.dev/bore/v2/pkg/errs"
 )
 
@@ -113,21 +115,27 @@ func (m *Manager) Read() (*bore.Config, error) {
 	return m.config, nil
 }
 
-func (m *Manager) SetDefaultCollectionID(identifier string) error {
+func (m *Manager) SetDefaultCollection(
+	ctx context.Context,
+	resolver bore.CollectionResolver,
+	identifier string,
+	options bore.ResolveCollectionOptions,
+) (*models.Collection, error) {
 	config, err := m.Read()
 	if err != nil {
-		return errs.Wrap(err, "failed to read config")
+		return nil, errs.Wrap(err, "failed to read config")
 	}
 
-	if err := config.SetDefaultCollection(identifier); err != nil {
-		return errs.Wrap(err, "failed to set default collection")
+	collection, err := config.SetDefaultCollection(ctx, resolver, identifier, options)
+	if err != nil {
+		return nil, errs.Wrap(err, "failed to set default collection")
 	}
 
 	if err := m.Write(config); err != nil {
-		return errs.Wrap(err, "failed to write config")
+		return nil, errs.Wrap(err, "failed to write config")
 	}
 
-	return nil
+	return collection, nil
 }
 
 func (m *Manager) UnsetDefaultCollectionID() error {
@@ -2,17 +2,26 @@ package handler
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/urfave/cli/v2"
 	"go.trulyao.dev/bore/v2"
+	"go.trulyao.dev/bore/v2/cmd/bore-cli/app/tui"
+	"go.trulyao.dev/bore/v2/database/models"
 	"go.trulyao.dev/bore/v2/pkg/errs"
+	"go.trulyao.dev/bore/v2/pkg/lib"
 	"go.trulyao.dev/bore/v2/pkg/mimetype"
 )
 
@@ -27,6 +36,32 @@ type CliCopyOptions struct {
 	Stdin bool
 }
 
+// ContentEncoding is how the content is encoded in JSON output.
+type ContentEncoding string
+
+const (
+	ContentEncodingUTF8   ContentEncoding = "utf8"
+	ContentEncodingBase64 ContentEncoding = "base64"
+)
+
+// pastedContent is the JSON output of paste for content that does not belong to an item, like the system clipboard.
+// Content that is not valid UTF-8 is base64-encoded so it survives the round trip.
+type pastedContent struct {
+	Content  string          `json:"content"`
+	Encoding ContentEncoding `json:"encoding"`
+	Size     int             `json:"size"`
+	Hash     string          `json:"hash,omitempty"` // SHA-256 of the content, left out for masked items.
+}
+
+// pastedItem is the JSON output of paste.
+type pastedItem struct {
+	ID       string `json:"id"`
+	Mimetype string `json:"mimetype"`
+	pastedContent
+	CollectionID string `json:"collection_id"`
+	CreatedAt    string `json:"created_at"`
+}
+
 func (h *Handler) Copy(ctx *cli.Context, options CliCopyOptions) error {
 	config, err := h.configManager.Read()
 	if err != nil {
@@ -43,39 +78,39 @@ func (h *Handler) Copy(ctx *cli.Context, options CliCopyOptions) error {
 		return cli.Exit("invalid format for copy command: "+string(format), 1)
 	}
 
-	rawMimeType := ctx.String(FlagMimeType)
-	if rawMimeType == "" {
-		rawMimeType = "text/plain"
-	}
-	mimeType, err := mimetype.ParseMimeType(rawMimeType)
-	if err != nil {
-		return cli.Exit("invalid mime type: "+err.Error(), 1)
+	// An empty mimetype is detected from the content when the item is created.
+	var mimeType mimetype.MimeType
+	if rawMimeType := strings.TrimSpace(ctx.String(FlagMimeType)); rawMimeType != "" && rawMimeType != MimeTypeAuto {
+		if mimeType, err = mimetype.ParseMimeType(rawMimeType); err != nil {
+			return cli.Exit("invalid mime type: "+err.Error(), 1)
+		}
 	}
 
-	var content []byte
+	// Files and stdin are streamed, content above the blob threshold is then never held in memory as a whole.
+	var reader io.Reader
 
 	switch {
 	case inputFile != "":
-		content, err = os.ReadFile(inputFile)
+		file, err := os.Open(inputFile)
 		if err != nil {
 			return cli.Exit("failed to read input file: "+err.Error(), 1)
 		}
+		defer func() { _ = file.Close() }()
+
+		reader = file
 
 	case options.Stdin:
-		reader := bufio.NewReader(ctx.App.Reader)
-		content, err = io.ReadAll(reader)
-		if err != nil {
-			return cli.Exit("failed to read from stdin: "+err.Error(), 1)
-		}
+		reader = ctx.App.Reader
 
 	case ctx.NArg() == 0:
-		reader := bufio.NewReader(ctx.App.Reader)
+		var content []byte
+		input := bufio.NewReader(ctx.App.Reader)
 		fmt.Println(
 			"No input argument provided. Please enter the content to copy (end with Ctrl+D):",
 		)
 
 		for {
-			line, err := reader.ReadBytes('\n')
+			line, err := input.ReadBytes('\n')
 			if err != nil && err != io.EOF {
 				return cli.Exit("failed to read from stdin: "+err.Error(), 1)
 			}
@@ -90,29 +125,91 @@ func (h *Handler) Copy(ctx *cli.Context, options CliCopyOptions) error {
 			return cli.Exit("no content provided to copy", 1)
 		}
 		content = content[:len(content)-1] // Remove the last newline character
+		reader = bytes.NewReader(content)
 
 	case ctx.NArg() > 1:
 		return cli.Exit("too many arguments provided. Only one argument is allowed", 1)
 	default:
-		content = []byte(ctx.Args().First())
+		reader = strings.NewReader(ctx.Args().First())
 	}
 
 	if format == PasteFormatBase64 {
-		if content, err = h.decodebase64Content(content); err != nil {
-			return err
+		reader = base64.NewDecoder(base64.StdEncoding, reader)
+	}
+
+	if ctx.IsSet(FlagClearAfter) {
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			return cli.Exit("failed to read content: "+err.Error(), 1)
 		}
+
+		return h.copyEphemeral(ctx, content, ctx.Duration(FlagClearAfter))
+	}
+
+	collectionID, err := h.collectionFlag(ctx)
+	if err != nil {
+		return err
 	}
 
-	collectionID := ctx.String(FlagCollection)
 	if collectionID == "" {
 		collectionID = config.DefaultCollection
 	}
 
-	return h.bore.Clipboard().Set(ctx.Context, content, bore.SetClipboardOptions{
+	var expiresAt time.Time
+	if ttl := ctx.Duration(FlagTTL); ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	} else if ttl < 0 {
+		return cli.Exit("ttl must be a positive duration", 1)
+	}
+
+	maxPastes := ctx.Int(FlagMaxPastes)
+	if maxPastes < 0 {
+		return cli.Exit("max pastes must be a positive number", 1)
+	}
+
+	appendToLatest := ctx.Bool(FlagAppend)
+	if appendToLatest && (!expiresAt.IsZero() || maxPastes > 0) {
+		return cli.Exit("--append cannot be combined with --ttl or --max-pastes", 1)
+	}
+
+	sensitive := ctx.Bool(FlagSecret)
+	if appendToLatest && sensitive {
+		return cli.Exit("--append cannot be combined with --secret", 1)
+	}
+
+	result, err := h.bore.Clipboard().SetFrom(ctx.Context, reader, bore.SetClipboardOptions{
 		Passthrough:  ctx.Bool(FlagSystem),
 		CollectionID: collectionID,
 		Mimetype:     mimeType,
+		Filename:     inputFile,
+		ExpiresAt:    expiresAt,
+		MaxPastes:    maxPastes,
+		Append:       appendToLatest,
+		Separator:    unescapeSeparator(ctx.String(FlagSeparator)),
+		Sensitive:    sensitive,
 	})
+
+	var secretErr *errs.SecretDetectedError
+	if errors.As(err, &secretErr) {
+		return cli.Exit(secretErr.Error(), 1)
+	} else if err != nil {
+		return err
+	}
+
+	if result.Secret != nil {
+		_, _ = fmt.Fprintln(ctx.App.ErrWriter, "warning: "+result.Secret.String())
+	}
+
+	return nil
+}
+
+// unescapeSeparator interprets escape sequences like \n and \t in a separator passed on the command line.
+// Separators that are not valid escaped strings are used as-is.
+func unescapeSeparator(separator string) string {
+	if unquoted, err := strconv.Unquote(`"` + separator + `"`); err == nil {
+		return unquoted
+	}
+	return separator
 }
 
 func (h *Handler) Paste(ctx *cli.Context) error {
@@ -128,76 +225,175 @@ func (h *Handler) Paste(ctx *cli.Context) error {
 
 	outputFile := ctx.String(FlagOutputFile)
 
-	collectionID := ctx.String(FlagCollection)
+	collectionID, err := h.collectionFlag(ctx)
+	if err != nil {
+		return err
+	}
+
 	if collectionID == "" {
 		collectionID = config.DefaultCollection
 	}
 
-	item, err := h.bore.Get(ctx.Context, bore.GetClipboardOptions{
+	if !slices.Contains([]PasteFormat{PasteFormatText, PasteFormatBase64, PasteFormatJSON}, format) {
+		return cli.Exit("unsupported format: "+string(format), 1)
+	}
+
+	output, err := newPasteOutput(ctx.App.Writer, outputFile)
+	if err != nil {
+		return cli.Exit("failed to open output file: "+err.Error(), 1)
+	}
+	defer output.discard()
+
+	options := bore.GetClipboardOptions{
 		ItemID:              ctx.String(FlagIdentifier),
 		CollectionID:        collectionID,
 		FromSystemClipboard: ctx.Bool(FlagSystem),
 		DeleteAfterPaste:    ctx.Bool(FlagDelete),
+		ForceDelete:         ctx.Bool(FlagForce),
+		PurgeAfterPaste:     ctx.Bool(FlagPurge),
 		SkipCollectionCheck: false,
-	})
+	}
+
+	// Text and base64 are streamed so large items are never held in memory, JSON needs the whole content.
+	var item bore.PasteResult
+	switch format {
+	case PasteFormatText:
+		item, err = h.bore.GetTo(ctx.Context, output, options)
+	case PasteFormatBase64:
+		encoder := base64.NewEncoder(base64.StdEncoding, output)
+		if item, err = h.bore.GetTo(ctx.Context, encoder, options); err == nil {
+			err = encoder.Close()
+		}
+	case PasteFormatJSON:
+		if item, err = h.bore.Get(ctx.Context, options); err == nil {
+			err = writeJSONItem(output, item, ctx.Bool(FlagReveal))
+		}
+	}
 	if err != nil {
 		return cli.Exit("failed to paste content: "+err.Error(), 1)
 	}
 
-	var content []byte
-	if content, err = h.contentToFormat(item, format); err != nil {
-		return err
+	if item.Deleted {
+		_, _ = fmt.Fprintln(ctx.App.ErrWriter, "item "+item.Item.ID+" was deleted, its content was recovered from the history")
 	}
 
-	if outputFile != "" {
-		return h.writeToFile(ctx, outputFile, content)
+	if err := output.commit(); err != nil {
+		return cli.Exit("failed to write output file: "+err.Error(), 1)
 	}
 
-	return h.writeToStdout(ctx, content)
-}
-
-func (h *Handler) writeToFile(_ *cli.Context, filename string, content []byte) error {
-	return os.WriteFile(filename, content, 0o644)
+	return nil
 }
 
-func (h *Handler) writeToStdout(ctx *cli.Context, content []byte) error {
-	_, err := ctx.App.Writer.Write(content)
-	return err
+// pasteOutput is where pasted content is written.
+// A regular output file is written next to its destination and only moved into place once the paste has succeeded, so a failed paste leaves it untouched.
+type pasteOutput struct {
+	io.Writer
+	file   *os.File
+	target string // The path the file is renamed to on commit, empty if the file is written in place.
 }
 
-func (h *Handler) contentToFormat(result bore.PasteResult, format PasteFormat) ([]byte, error) {
-	switch format {
-	case PasteFormatText:
-		return result.Content, nil
-
-	case PasteFormatBase64:
-		base64Content := make([]byte, base64.StdEncoding.EncodedLen(len(result.Content)))
-		base64.StdEncoding.Encode(base64Content, result.Content)
-		return base64Content, nil
+func newPasteOutput(stdout io.Writer, path string) (*pasteOutput, error) {
+	if path == "" {
+		return &pasteOutput{Writer: stdout, file: nil, target: ""}, nil
+	}
 
-	case PasteFormatJSON:
-		jsonContent, err := json.Marshal(map[string]string{
-			"id":            result.Item.ID,
-			"mimetype":      result.Item.Mimetype,
-			"content":       string(result.Content),
-			"collection_id": result.Item.CollectionID.String,
-			"created_at":    result.Item.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		})
+	// Devices and pipes (like /dev/stdout) can not be replaced, they are written to directly.
+	if info, err := os.Stat(path); err == nil && !info.Mode().IsRegular() {
+		file, err := os.OpenFile(path, os.O_WRONLY, 0)
 		if err != nil {
 			return nil, err
 		}
-		return jsonContent, nil
 
-	default:
-		return nil, cli.Exit("unsupported format: "+string(format), 1)
+		return &pasteOutput{Writer: file, file: file, target: ""}, nil
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), ".bore-paste-*")
+	if err != nil {
+		return nil, err
+	}
+
+	return &pasteOutput{Writer: file, file: file, target: path}, nil
+}
+
+// commit closes the output file and moves it into place.
+func (o *pasteOutput) commit() error {
+	if o.file == nil {
+		return nil
+	}
+
+	if o.target != "" {
+		if err := o.file.Chmod(0o644); err != nil {
+			return err
+		}
+	}
+
+	if err := o.file.Close(); err != nil || o.target == "" {
+		return err
+	}
+
+	return os.Rename(o.file.Name(), o.target)
+}
+
+// discard removes the temporary output file, it does nothing once the output has been committed.
+func (o *pasteOutput) discard() {
+	if o.file == nil {
+		return
+	}
+
+	_ = o.file.Close()
+	if o.target != "" {
+		_ = os.Remove(o.file.Name())
 	}
 }
 
-func (h *Handler) decodebase64Content(content []byte) ([]byte, error) {
-	decodedContent := make([]byte, base64.StdEncoding.DecodedLen(len(content)))
-	n, err := base64.StdEncoding.Decode(decodedContent, content)
+// writeItemContent streams the content of the item to w, wherever it is stored.
+func (h *Handler) writeItemContent(w io.Writer, item *models.Item) error {
+	reader, err := h.bore.Clipboard().OpenContent(item)
 	if err != nil {
-		return nil, cli.Exit("failed to decode base64 content: "+err.Error(), 1)
+		return err
+	}
+	defer func() { _ = reader.Close() }()
+
+	_, err = io.Copy(w, reader)
+	return err
+}
+
+// writeJSONItem writes the pasted item as JSON, the content of sensitive items is masked unless reveal is set.
+// Content without an item (from the system clipboard) is written without the item's fields, and `null` is written if there was nothing to paste.
+func writeJSONItem(w io.Writer, result bore.PasteResult, reveal bool) error {
+	content := pastedContent{
+		Content:  string(result.Content),
+		Encoding: ContentEncodingUTF8,
+		Size:     len(result.Content),
+		Hash:     lib.ComputeChecksum(result.Content),
 	}
-	return decodedContent[:n], nil
+
+	switch {
+	case result.Item != nil && result.Item.Sensitive && !reveal:
+		content.Content, content.Hash = tui.MaskedContent, ""
+	case !utf8.Valid(result.Content):
+		content.Content, content.Encoding = base64.StdEncoding.EncodeToString(result.Content), ContentEncodingBase64
+	}
+
+	var output any
+	switch {
+	case result.Item != nil:
+		output = pastedItem{
+			ID:            result.Item.ID,
+			Mimetype:      result.Item.Mimetype,
+			pastedContent: content,
+			CollectionID:  result.Item.CollectionID.String,
+			CreatedAt:     result.Item.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		}
+	case len(result.Content) > 0:
+		output = content
+	}
+
+	jsonContent, err := json.Marshal(output)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(jsonContent)
+	return err
 }
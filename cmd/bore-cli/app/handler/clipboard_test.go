@@ -0,0 +1,70 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/urfave/cli/v2"
+	"go.trulyao.dev/bore/v2"
+	"go.trulyao.dev/bore/v2/cmd/bore-cli/app/config"
+	"go.trulyao.dev/bore/v2/cmd/bore-cli/app/handler"
+)
+
+// paste runs the paste handler with the given arguments and returns what it wrote.
+func paste(t *testing.T, b *bore.Bore, configManager *config.Manager, args ...string) string {
+	t.Helper()
+
+	var output bytes.Buffer
+	h := handler.New(b, nil, configManager)
+
+	// nolint:exhaustruct
+	app := &cli.App{
+		Writer: &output,
+		Flags:  []cli.Flag{&cli.StringFlag{Name: handler.FlagFormat}},
+		Action: h.Paste,
+	}
+
+	if err := app.Run(append([]string{"bore"}, args...)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	return output.String()
+}
+
+func Test_PasteJSON(t *testing.T) {
+	dir := t.TempDir()
+	configManager, err := config.NewManager(config.Options{
+		ConfigPath: filepath.Join(dir, "config.toml"),
+		DataDir:    filepath.Join(dir, "data"),
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	b, err := bore.New(&bore.Config{DataDir: configManager.DataDir()})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	t.Cleanup(func() { _ = b.Close() })
+
+	if output := paste(t, b, configManager, "--format", "json"); output != "null" {
+		t.Errorf("expected null for an empty history, got %q", output)
+	}
+
+	content := []byte{0xff, 0x00, 'b', 'o', 'r', 'e'}
+	if _, err := b.Clipboard().Set(context.Background(), content, bore.SetClipboardOptions{}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var item map[string]any
+	if err := json.Unmarshal([]byte(paste(t, b, configManager, "--format", "json")), &item); err != nil {
+		t.Fatalf("expected valid JSON, got %v", err)
+	}
+
+	if item["id"] == "" || item["encoding"] != string(handler.ContentEncodingBase64) || item["content"] != "/wBib3Jl" {
+		t.Errorf("expected the item with base64-encoded content, got %v", item)
+	}
+}
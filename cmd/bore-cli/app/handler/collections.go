@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/oklog/ulid/v2"
 	"github.com/urfave/cli/v2"
 	"go.trulyao.dev/bore/v2"
 )
@@ -25,7 +24,17 @@ func (h *Handler) ListCollections(c *cli.Context) error {
 		return h.tuiManager.RenderJSON(c.App.Writer, collections)
 	}
 
-	return h.tuiManager.RenderCollectionsList(c.App.Writer, collections)
+	ids := make([]string, 0, len(collections))
+	for _, collection := range collections {
+		ids = append(ids, collection.ID)
+	}
+
+	shortIDs, err := h.bore.Collections().ShortIDs(c.Context, ids)
+	if err != nil {
+		return err
+	}
+
+	return h.tuiManager.RenderCollectionsList(c.App.Writer, collections, shortIDs)
 }
 
 // CreateCollection creates a new collection with the given name.
@@ -78,7 +87,7 @@ func (h *Handler) DeleteCollection(c *cli.Context) error {
 		_, _ = c.App.Writer.Write(
 			fmt.Appendf(
 				[]byte{},
-				"Are you sure you want to delete the collection %q (ID: %s)? This action cannot be undone. (y/N): ",
+				"Are you sure you want to delete the collection %q (ID: %s)? It can be restored from the trash. (y/N): ",
 				collection.Name,
 				collection.ID,
 			),
@@ -90,7 +99,9 @@ func (h *Handler) DeleteCollection(c *cli.Context) error {
 		}
 	}
 
-	if err := h.bore.Collections().Delete(c.Context, collectionID); err != nil {
+	if err := h.bore.Collections().Delete(c.Context, collection.ID, bore.DeleteCollectionOptions{
+		Force: c.Bool(FlagIncludePinned),
+	}); err != nil {
 		return err
 	}
 
@@ -115,12 +126,7 @@ func (h *Handler) RenameCollection(c *cli.Context) error {
 		return cli.Exit("new name is required", 1)
 	}
 
-	id, err := ulid.Parse(collectionID)
-	if err != nil {
-		return cli.Exit("invalid collection id", 1)
-	}
-
-	collection, err := h.bore.Collections().Get(c.Context, id.String())
+	collection, err := h.bore.Collections().Get(c.Context, collectionID)
 	if err != nil {
 		return err
 	}
@@ -129,7 +135,7 @@ func (h *Handler) RenameCollection(c *cli.Context) error {
 		return cli.Exit("collection not found", 1)
 	}
 
-	if err = h.bore.Collections().Rename(c.Context, id.String(), newName); err != nil {
+	if err = h.bore.Collections().Rename(c.Context, collection.ID, newName); err != nil {
 		return err
 	}
 
@@ -147,21 +153,16 @@ func (h *Handler) SetDefaultCollection(c *cli.Context) error {
 		return cli.Exit("too many arguments", 1)
 	}
 
-	collectionID := c.Args().First()
-
-	collection, err := h.bore.Collections().Get(c.Context, collectionID)
+	collection, err := h.configManager.SetDefaultCollection(
+		c.Context,
+		h.bore.Collections(),
+		c.Args().First(),
+		bore.ResolveCollectionOptions{Create: c.Bool(FlagCreate)},
+	)
 	if err != nil {
 		return err
 	}
 
-	if collection == nil {
-		return cli.Exit("collection not found", 1)
-	}
-
-	if err := h.configManager.SetDefaultCollectionID(collection.ID); err != nil {
-		return err
-	}
-
 	_, _ = c.App.Writer.Write([]byte(collection.ID + "\n"))
 	return nil
 }
@@ -0,0 +1,13 @@
+//go:build unix
+
+package handler
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach starts the command in a new session, so it is not killed with the terminal that started it.
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
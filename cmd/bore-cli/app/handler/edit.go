@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/urfave/cli/v2"
+	"go.trulyao.dev/bore/v2"
+	"go.trulyao.dev/bore/v2/pkg/errs"
+)
+
+// defaultEditor is used when neither $VISUAL nor $EDITOR is set.
+const defaultEditor = "vi"
+
+// EditItem opens the content of an item in the user's editor and saves the result as the new content.
+// The edit is rejected if the item was changed by anyone else while the editor was open.
+func (h *Handler) EditItem(c *cli.Context) error {
+	if c.NArg() > 1 {
+		return cli.Exit("too many arguments", 1)
+	}
+
+	found, err := h.bore.Clipboard().Find(c.Context, c.Args().First(), c.String(FlagCollection))
+	if err != nil {
+		return cli.Exit("failed to find item: "+err.Error(), 1)
+	}
+
+	if found.Item.InBlobStore() {
+		return cli.Exit("cannot edit content above the blob threshold", 1)
+	}
+
+	if !utf8.Valid(found.Item.Content) {
+		return cli.Exit("cannot edit binary content", 1)
+	}
+
+	edited, err := editInEditor(c, found.Item.Content)
+	if err != nil {
+		return cli.Exit("failed to edit item: "+err.Error(), 1)
+	}
+
+	if bytes.Equal(edited, found.Item.Content) {
+		_, _ = fmt.Fprintln(c.App.Writer, "No changes.")
+		return nil
+	}
+
+	if len(bytes.TrimSpace(edited)) == 0 {
+		return cli.Exit("aborted, the edited content is empty", 1)
+	}
+
+	result, err := h.bore.Clipboard().Update(c.Context, found.Item.ID, edited, bore.UpdateItemOptions{
+		ExpectedVersion: found.Version,
+	})
+	if errors.Is(err, errs.ErrItemModified) {
+		return cli.Exit("failed to save item: "+err.Error()+", your changes were not saved", 1)
+	} else if err != nil {
+		return cli.Exit("failed to save item: "+err.Error(), 1)
+	}
+
+	if result.Merged {
+		_, _ = fmt.Fprintln(c.App.Writer, "merged into existing item "+result.ItemID)
+		return nil
+	}
+
+	_, _ = fmt.Fprintln(c.App.Writer, result.ItemID)
+	return nil
+}
+
+// editInEditor writes the content to a temporary file, opens it in $VISUAL or $EDITOR and returns the saved content.
+func editInEditor(c *cli.Context, content []byte) ([]byte, error) {
+	file, err := os.CreateTemp("", "bore-edit-*.txt")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.Remove(file.Name()) }()
+
+	if _, err := file.Write(content); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	if err := file.Close(); err != nil {
+		return nil, err
+	}
+
+	editor := strings.TrimSpace(os.Getenv("VISUAL"))
+	if editor == "" {
+		editor = strings.TrimSpace(os.Getenv("EDITOR"))
+	}
+	if editor == "" {
+		editor = defaultEditor
+	}
+
+	// The editor may come with arguments of its own, e.g. "code --wait".
+	args := strings.Fields(editor)
+	cmd := exec.CommandContext(c.Context, args[0], append(args[1:], file.Name())...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("editor %q exited with an error: %w", editor, err)
+	}
+
+	return os.ReadFile(file.Name())
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/urfave/cli/v2"
+	"go.trulyao.dev/bore/v2/pkg/errs"
+)
+
+const (
+	// CommandClearClipboard is the hidden command run by the helper process started by `copy --clear-after`.
+	CommandClearClipboard = "clear-clipboard"
+
+	// envClearChecksum passes the checksum of the copied content to the helper, the environment is not visible to other users like the arguments are.
+	envClearChecksum = "BORE_CLEAR_CHECKSUM"
+)
+
+// copyEphemeral writes the content to the system clipboard without recording it, and starts a detached helper that clears it after clearAfter.
+func (h *Handler) copyEphemeral(ctx *cli.Context, content []byte, clearAfter time.Duration) error {
+	if clearAfter < 0 {
+		return cli.Exit("clear-after must be a positive duration", 1)
+	}
+
+	if !ctx.Bool(FlagSystem) {
+		return cli.Exit("--clear-after requires --system, items in the history expire with --ttl instead", 1)
+	}
+
+	for _, flag := range []string{FlagCollection, FlagTTL, FlagMaxPastes, FlagAppend, FlagSecret} {
+		if ctx.IsSet(flag) {
+			return cli.Exit("--clear-after cannot be combined with --"+flag, 1)
+		}
+	}
+
+	checksum, err := h.bore.Clipboard().CopyEphemeral(ctx.Context, content)
+	if err != nil {
+		if errors.Is(err, errs.ErrClipboardUnavailable) {
+			return ErrClipboardNotAvailable
+		}
+		return cli.Exit(err.Error(), 1)
+	}
+
+	if err := h.startClearHelper(checksum, clearAfter); err != nil {
+		return cli.Exit("copied, but failed to schedule clearing the clipboard: "+err.Error(), 1)
+	}
+
+	return nil
+}
+
+// startClearHelper runs `bore clear-clipboard` in the background, detached from the terminal so it outlives this process.
+func (h *Handler) startClearHelper(checksum string, clearAfter time.Duration) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(
+		executable,
+		"--"+FlagConfig, h.configManager.ConfigPath(),
+		"--"+FlagDataDir, h.configManager.DataDir(),
+		CommandClearClipboard,
+		"--"+FlagClearAfter, clearAfter.String(),
+	)
+	cmd.Env = append(os.Environ(), envClearChecksum+"="+checksum)
+	detach(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	return cmd.Process.Release()
+}
+
+// ClearClipboard waits for --clear-after, then clears the system clipboard if it still holds the content that was copied.
+func (h *Handler) ClearClipboard(c *cli.Context) error {
+	checksum := os.Getenv(envClearChecksum)
+	if checksum == "" {
+		return cli.Exit("$"+envClearChecksum+" is not set", 1)
+	}
+
+	select {
+	case <-time.After(c.Duration(FlagClearAfter)):
+	case <-c.Context.Done():
+		return c.Context.Err()
+	}
+
+	cleared, err := h.bore.Clipboard().ClearEphemeral(c.Context, checksum)
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+
+	if !cleared {
+		_, _ = fmt.Fprintln(c.App.ErrWriter, "clipboard content changed, left it untouched")
+	}
+
+	return nil
+}
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"strings"
+
 	"github.com/urfave/cli/v2"
 	"go.trulyao.dev/bore/v2"
 	"go.trulyao.dev/bore/v2/cmd/bore-cli/app/config"
@@ -17,6 +19,9 @@ const (
 	PasteFormatBase64 PasteFormat = "base64"
 )
 
+// MimeTypeAuto is the --mime-type value that detects the mimetype from the content.
+const MimeTypeAuto = "auto"
+
 const (
 	FlagConfig     = "config"
 	FlagCollection = "collection"
@@ -30,6 +35,24 @@ const (
 	FlagInputFile  = "input-file"
 	FlagMimeType   = "mime-type"
 	FlagOutputFile = "output-file"
+	FlagLimit      = "limit"
+	FlagCursor     = "cursor"
+	FlagSince      = "since"
+	FlagUntil      = "until"
+	FlagRegex      = "regex"
+	FlagDryRun     = "dry-run"
+	FlagTTL        = "ttl"
+	FlagMaxPastes  = "max-pastes"
+	FlagCreate     = "create"
+	FlagAppend     = "append"
+	FlagSeparator  = "separator"
+	FlagKeyFile    = "key-file"
+	FlagPurge      = "purge"
+	FlagSecret     = "secret"
+	FlagReveal     = "reveal"
+	FlagClearAfter = "clear-after"
+
+	FlagIncludePinned = "include-pinned"
 )
 
 type Handler struct {
@@ -45,3 +68,21 @@ func New(bore *bore.Bore, tuiManager *tui.Manager, configManager *config.Manager
 		configManager: configManager,
 	}
 }
+
+// collectionFlag returns the ID of the collection passed with --collection by name, ID or unique ID prefix, or an empty string if the flag is not set.
+// Missing collections are created if the command has a --create flag and it is set.
+func (h *Handler) collectionFlag(c *cli.Context) (string, error) {
+	identifier := strings.TrimSpace(c.String(FlagCollection))
+	if identifier == "" {
+		return "", nil
+	}
+
+	collection, err := h.bore.Collections().Resolve(c.Context, identifier, bore.ResolveCollectionOptions{
+		Create: c.Bool(FlagCreate),
+	})
+	if err != nil {
+		return "", cli.Exit(err.Error(), 1)
+	}
+
+	return collection.ID, nil
+}
@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+	"go.trulyao.dev/bore/v2"
+	"go.trulyao.dev/bore/v2/cmd/bore-cli/app/tui"
+)
+
+type historyItem struct {
+	ID             string     `json:"id"`
+	CollectionID   string     `json:"collection_id"`
+	CollectionName string     `json:"collection_name"`
+	Mimetype       string     `json:"mimetype"`
+	Size           int        `json:"size"`
+	Preview        string     `json:"preview"`
+	Sensitive      bool       `json:"sensitive"`
+	PinnedAt       *time.Time `json:"pinned_at"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+type historyPage struct {
+	Items      []historyItem `json:"items"`
+	NextCursor int64         `json:"next_cursor"`
+}
+
+// History lists clipboard items, most recent first.
+func (h *Handler) History(c *cli.Context) error {
+	format := PasteFormat(c.String(FlagFormat))
+	if format == "" {
+		format = PasteFormatText
+	}
+
+	if format != PasteFormatText && format != PasteFormatJSON {
+		return cli.Exit("invalid format for history command: "+string(format), 1)
+	}
+
+	createdAfter, err := parseTimeFlag(c.String(FlagSince))
+	if err != nil {
+		return cli.Exit("invalid --"+FlagSince+" value: "+err.Error(), 1)
+	}
+
+	createdBefore, err := parseTimeFlag(c.String(FlagUntil))
+	if err != nil {
+		return cli.Exit("invalid --"+FlagUntil+" value: "+err.Error(), 1)
+	}
+
+	collectionID, err := h.collectionFlag(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := h.bore.Clipboard().List(c.Context, bore.ListClipboardOptions{
+		CollectionID:  collectionID,
+		Mimetype:      c.String(FlagMimeType),
+		CreatedAfter:  createdAfter,
+		CreatedBefore: createdBefore,
+		UpdatedAfter:  time.Time{},
+		UpdatedBefore: time.Time{},
+		PinnedFirst:   true,
+		Cursor:        c.Int64(FlagCursor),
+		Limit:         c.Int(FlagLimit),
+	})
+	if err != nil {
+		return cli.Exit("failed to list history: "+err.Error(), 1)
+	}
+
+	reveal := c.Bool(FlagReveal)
+	if format == PasteFormatJSON {
+		page := historyPage{
+			Items:      make([]historyItem, 0, len(result.Items)),
+			NextCursor: result.NextCursor,
+		}
+
+		for _, item := range result.Items {
+			entry := historyItem{
+				ID:             item.ID,
+				CollectionID:   item.CollectionID.String,
+				CollectionName: "",
+				Mimetype:       item.Mimetype,
+				Size:           int(item.Size()),
+				Preview:        tui.ItemPreview(item, 80, item.Sensitive && !reveal),
+				Sensitive:      item.Sensitive,
+				PinnedAt:       nil,
+				ExpiresAt:      nil,
+				CreatedAt:      item.CreatedAt,
+				UpdatedAt:      item.UpdatedAt,
+			}
+			if item.Collection != nil {
+				entry.CollectionName = item.Collection.Name
+			}
+			if item.IsPinned() {
+				entry.PinnedAt = &item.PinnedAt.Time
+			}
+			if !item.ExpiresAt.IsZero() {
+				entry.ExpiresAt = &item.ExpiresAt.Time
+			}
+			page.Items = append(page.Items, entry)
+		}
+
+		return h.tuiManager.RenderJSON(c.App.Writer, page)
+	}
+
+	ids := make([]string, 0, len(result.Items))
+	for _, item := range result.Items {
+		ids = append(ids, item.ID)
+	}
+
+	shortIDs, err := h.bore.Clipboard().ShortIDs(c.Context, ids)
+	if err != nil {
+		return err
+	}
+
+	if err := h.tuiManager.RenderItemsList(c.App.Writer, result.Items, shortIDs, reveal); err != nil {
+		return err
+	}
+
+	if result.NextCursor != 0 {
+		_, _ = fmt.Fprintf(
+			c.App.ErrWriter,
+			"\nMore items available, use --%s %d to see the next page.\n",
+			FlagCursor,
+			result.NextCursor,
+		)
+	}
+
+	return nil
+}
+
+// parseTimeFlag parses either an absolute time (RFC 3339 or YYYY-MM-DD) or a duration relative to now (e.g. 24h).
+func parseTimeFlag(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if d, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-d), nil
+	}
+
+	for _, layout := range []string{time.RFC3339, time.DateTime, time.DateOnly} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("expected a duration (e.g. 24h) or a date (e.g. 2006-01-02), got %q", value)
+}
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+	"go.trulyao.dev/bore/v2"
+	"go.trulyao.dev/bore/v2/cmd/bore-cli/app/tui"
+)
+
+// PinItem pins an item so it is listed first and protected from deletion.
+func (h *Handler) PinItem(c *cli.Context) error {
+	itemID, err := itemIDArg(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.bore.Clipboard().Pin(c.Context, itemID); err != nil {
+		return cli.Exit("failed to pin item: "+err.Error(), 1)
+	}
+
+	_, _ = fmt.Fprintln(c.App.Writer, itemID)
+	return nil
+}
+
+// UnpinItem removes the pin from an item.
+func (h *Handler) UnpinItem(c *cli.Context) error {
+	itemID, err := itemIDArg(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.bore.Clipboard().Unpin(c.Context, itemID); err != nil {
+		return cli.Exit("failed to unpin item: "+err.Error(), 1)
+	}
+
+	_, _ = fmt.Fprintln(c.App.Writer, itemID)
+	return nil
+}
+
+// MoveItem moves an item to another collection, an empty collection makes the item uncategorized.
+func (h *Handler) MoveItem(c *cli.Context) error {
+	if c.NArg() != 2 {
+		return cli.Exit("expected an item id and a collection", 1)
+	}
+
+	itemID := strings.TrimSpace(c.Args().Get(0))
+	if itemID == "" {
+		return cli.Exit("item id is required", 1)
+	}
+
+	collectionID := ""
+	if identifier := strings.TrimSpace(c.Args().Get(1)); identifier != "" {
+		collection, err := h.bore.Collections().Resolve(c.Context, identifier, bore.ResolveCollectionOptions{
+			Create: c.Bool(FlagCreate),
+		})
+		if err != nil {
+			return cli.Exit("failed to move item: "+err.Error(), 1)
+		}
+
+		collectionID = collection.ID
+	}
+
+	result, err := h.bore.Clipboard().Move(c.Context, itemID, collectionID)
+	if err != nil {
+		return cli.Exit("failed to move item: "+err.Error(), 1)
+	}
+
+	if result.Merged {
+		_, _ = fmt.Fprintln(c.App.Writer, "merged into existing item "+result.ItemID)
+		return nil
+	}
+
+	_, _ = fmt.Fprintln(c.App.Writer, result.ItemID)
+	return nil
+}
+
+// ItemTimeline shows the history of an item, which also works for items that have been deleted.
+func (h *Handler) ItemTimeline(c *cli.Context) error {
+	if c.NArg() > 1 {
+		return cli.Exit("too many arguments", 1)
+	}
+
+	timeline, err := h.bore.Clipboard().Timeline(c.Context, c.Args().First(), c.String(FlagCollection))
+	if err != nil {
+		return cli.Exit("failed to load item timeline: "+err.Error(), 1)
+	}
+
+	reveal := c.Bool(FlagReveal)
+	if c.String(FlagFormat) == string(PasteFormatJSON) {
+		if timeline.Sensitive && !reveal {
+			timeline.Content = []byte(tui.MaskedContent)
+		}
+		return h.tuiManager.RenderJSON(c.App.Writer, timeline)
+	}
+
+	return h.tuiManager.RenderItemTimeline(c.App.Writer, timeline, reveal)
+}
+
+// ForgetItem permanently erases an item and its content from the history, confirming with the user first unless --force is used.
+func (h *Handler) ForgetItem(c *cli.Context) error {
+	identifier, err := itemIDArg(c)
+	if err != nil {
+		return err
+	}
+
+	if !c.Bool(FlagForce) {
+		var confirmation string
+		_, _ = fmt.Fprintf(
+			c.App.Writer,
+			"Are you sure you want to permanently erase %s and its content from the history? This action cannot be undone. (y/N): ",
+			identifier,
+		)
+		_, _ = fmt.Scanln(&confirmation)
+		if confirmation != "y" && confirmation != "Y" {
+			_, _ = fmt.Fprintln(c.App.Writer, "Aborted.")
+			return nil
+		}
+	}
+
+	itemID, err := h.bore.Clipboard().Purge(
+		c.Context,
+		identifier,
+		c.String(FlagCollection),
+		bore.DeleteItemOptions{Force: c.Bool(FlagForce), Purge: true},
+	)
+	if err != nil {
+		return cli.Exit("failed to forget item: "+err.Error(), 1)
+	}
+
+	_, _ = fmt.Fprintln(c.App.Writer, "Forgot item "+itemID)
+	return nil
+}
+
+// itemIDArg returns the single item ID argument of the current command.
+func itemIDArg(c *cli.Context) (string, error) {
+	if c.NArg() == 0 {
+		return "", cli.Exit("item id is required", 1)
+	} else if c.NArg() > 1 {
+		return "", cli.Exit("too many arguments", 1)
+	}
+
+	itemID := strings.TrimSpace(c.Args().First())
+	if itemID == "" {
+		return "", cli.Exit("item id is required", 1)
+	}
+
+	return itemID, nil
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+	"go.trulyao.dev/bore/v2"
+	"golang.org/x/term"
+)
+
+// EnvNewPassphrase is read by `key rotate` for the new passphrase, since the configured variable still holds the current one.
+const EnvNewPassphrase = "BORE_NEW_PASSPHRASE"
+
+// InitKey encrypts the existing data with a passphrase, or with the key in --key-file (which is generated if it does not exist).
+func (h *Handler) InitKey(c *cli.Context) error {
+	config, err := h.configManager.Read()
+	if err != nil {
+		return err
+	}
+
+	envName := config.Encryption.PassphraseEnvName()
+	source, err := h.keySource(c, envName)
+	if err != nil {
+		return err
+	}
+
+	if err := h.bore.Keys().Init(c.Context, source); err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+
+	if err := h.configManager.Write(config); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintln(c.App.Writer, "Encrypted all items and history")
+	if source.KeyFile == "" && os.Getenv(envName) == "" {
+		_, _ = fmt.Fprintf(c.App.Writer, "Set $%s to the passphrase to use bore from now on\n", envName)
+	}
+
+	return nil
+}
+
+// RotateKey re-encrypts the data with a new passphrase, or with the key in --key-file.
+func (h *Handler) RotateKey(c *cli.Context) error {
+	config, err := h.configManager.Read()
+	if err != nil {
+		return err
+	}
+
+	source, err := h.keySource(c, EnvNewPassphrase)
+	if err != nil {
+		return err
+	}
+
+	if err := h.bore.Keys().Rotate(c.Context, source); err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+
+	if err := h.configManager.Write(config); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintln(c.App.Writer, "Re-encrypted all items and history with the new key")
+	if source.KeyFile == "" {
+		_, _ = fmt.Fprintf(
+			c.App.Writer,
+			"Set $%s to the new passphrase to use bore from now on\n",
+			config.Encryption.PassphraseEnvName(),
+		)
+	}
+
+	return nil
+}
+
+// keySource reads the new key from --key-file, the environment variable or a passphrase prompt, in that order.
+func (h *Handler) keySource(c *cli.Context, envName string) (bore.KeySource, error) {
+	if path := strings.TrimSpace(c.String(FlagKeyFile)); path != "" {
+		path, err := filepath.Abs(path)
+		if err != nil {
+			return bore.KeySource{}, err
+		}
+
+		return bore.KeySource{KeyFile: path}, nil
+	}
+
+	if passphrase := os.Getenv(envName); passphrase != "" {
+		return bore.KeySource{Passphrase: []byte(passphrase)}, nil
+	}
+
+	passphrase, err := promptNewPassphrase(c)
+	if err != nil {
+		return bore.KeySource{}, cli.Exit(
+			fmt.Sprintf("%s, set $%s or use --%s", err.Error(), envName, FlagKeyFile),
+			1,
+		)
+	}
+
+	return bore.KeySource{Passphrase: passphrase}, nil
+}
+
+func promptNewPassphrase(c *cli.Context) ([]byte, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, errors.New("no passphrase provided")
+	}
+
+	_, _ = fmt.Fprint(c.App.ErrWriter, "New passphrase: ")
+	passphrase, err := term.ReadPassword(fd)
+	_, _ = fmt.Fprintln(c.App.ErrWriter)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(passphrase) == 0 {
+		return nil, errors.New("passphrase cannot be empty")
+	}
+
+	_, _ = fmt.Fprint(c.App.ErrWriter, "Confirm passphrase: ")
+	confirmation, err := term.ReadPassword(fd)
+	_, _ = fmt.Fprintln(c.App.ErrWriter)
+	if err != nil {
+		return nil, err
+	}
+
+	if string(confirmation) != string(passphrase) {
+		return nil, errors.New("passphrases do not match")
+	}
+
+	return passphrase, nil
+}
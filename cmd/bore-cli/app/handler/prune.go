@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+	"go.trulyao.dev/bore/v2"
+	"go.trulyao.dev/bore/v2/cmd/bore-cli/app/tui"
+)
+
+type prunedItem struct {
+	ID             string `json:"id"`
+	CollectionID   string `json:"collection_id"`
+	CollectionName string `json:"collection_name"`
+	Size           int64  `json:"size"`
+	Reason         string `json:"reason"`
+}
+
+type pruneReport struct {
+	DryRun     bool         `json:"dry_run"`
+	Items      []prunedItem `json:"items"`
+	FreedBytes int64        `json:"freed_bytes"`
+}
+
+// Prune applies the configured retention policies.
+func (h *Handler) Prune(c *cli.Context) error {
+	format := PasteFormat(c.String(FlagFormat))
+	if format == "" {
+		format = PasteFormatText
+	}
+
+	if format != PasteFormatText && format != PasteFormatJSON {
+		return cli.Exit("invalid format for prune command: "+string(format), 1)
+	}
+
+	dryRun := c.Bool(FlagDryRun)
+
+	result, err := h.bore.Clipboard().Prune(c.Context, bore.PruneOptions{DryRun: dryRun})
+	if err != nil {
+		return cli.Exit("failed to prune: "+err.Error(), 1)
+	}
+
+	if format == PasteFormatJSON {
+		report := pruneReport{
+			DryRun:     dryRun,
+			Items:      make([]prunedItem, 0, len(result.Items)),
+			FreedBytes: result.FreedBytes,
+		}
+
+		for _, item := range result.Items {
+			report.Items = append(report.Items, prunedItem{
+				ID:             item.ItemID,
+				CollectionID:   item.CollectionID,
+				CollectionName: item.CollectionName,
+				Size:           item.Size,
+				Reason:         string(item.Reason),
+			})
+		}
+
+		return h.tuiManager.RenderJSON(c.App.Writer, report)
+	}
+
+	if len(result.Items) == 0 {
+		_, _ = fmt.Fprintln(c.App.Writer, "Nothing to prune.")
+		return nil
+	}
+
+	rows := make([]tui.PrunedItem, 0, len(result.Items))
+	for _, item := range result.Items {
+		rows = append(rows, tui.PrunedItem{
+			ID:             item.ItemID,
+			CollectionName: item.CollectionName,
+			Size:           item.Size,
+			Reason:         string(item.Reason),
+		})
+	}
+
+	if err := h.tuiManager.RenderPrunedItems(c.App.Writer, rows); err != nil {
+		return err
+	}
+
+	verb := "Removed"
+	if dryRun {
+		verb = "Would remove"
+	}
+
+	_, _ = fmt.Fprintf(
+		c.App.Writer,
+		"\n%s %d item(s), %s.\n",
+		verb,
+		len(result.Items),
+		tui.FormatSize(int(result.FreedBytes)),
+	)
+	return nil
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+	"go.trulyao.dev/bore/v2"
+)
+
+const (
+	ansiBold  = "\x1b[1m"
+	ansiReset = "\x1b[0m"
+)
+
+type searchResult struct {
+	ID             string    `json:"id"`
+	CollectionID   string    `json:"collection_id"`
+	CollectionName string    `json:"collection_name"`
+	Mimetype       string    `json:"mimetype"`
+	Size           int       `json:"size"`
+	Snippet        string    `json:"snippet"`
+	Rank           float64   `json:"rank"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// Search searches clipboard items by content.
+func (h *Handler) Search(c *cli.Context) error {
+	if c.NArg() == 0 {
+		return cli.Exit("search query is required", 1)
+	}
+
+	format := PasteFormat(c.String(FlagFormat))
+	if format == "" {
+		format = PasteFormatText
+	}
+
+	if format != PasteFormatText && format != PasteFormatJSON {
+		return cli.Exit("invalid format for search command: "+string(format), 1)
+	}
+
+	// Highlight with ANSI escapes when printing to a terminal, and with plain markers anywhere else so the output stays readable in files and pipes.
+	highlightStart, highlightEnd := "**", "**"
+	if format == PasteFormatText && isTerminal(c.App.Writer) {
+		highlightStart, highlightEnd = ansiBold, ansiReset
+	}
+
+	collectionID, err := h.collectionFlag(c)
+	if err != nil {
+		return err
+	}
+
+	results, err := h.bore.Clipboard().Search(c.Context, bore.SearchOptions{
+		Query:          strings.Join(c.Args().Slice(), " "),
+		CollectionID:   collectionID,
+		Regex:          c.Bool(FlagRegex),
+		HighlightStart: highlightStart,
+		HighlightEnd:   highlightEnd,
+		Limit:          c.Int(FlagLimit),
+	})
+	if err != nil {
+		return cli.Exit("failed to search: "+err.Error(), 1)
+	}
+
+	if format == PasteFormatJSON {
+		output := make([]searchResult, 0, len(results))
+		for _, result := range results {
+			entry := searchResult{
+				ID:             result.ID,
+				CollectionID:   result.CollectionID.String,
+				CollectionName: "",
+				Mimetype:       result.Mimetype,
+				Size:           len(result.Content),
+				Snippet:        result.Snippet,
+				Rank:           result.Rank,
+				CreatedAt:      result.CreatedAt,
+			}
+			if result.Collection != nil {
+				entry.CollectionName = result.Collection.Name
+			}
+			output = append(output, entry)
+		}
+
+		return h.tuiManager.RenderJSON(c.App.Writer, output)
+	}
+
+	ids := make([]string, 0, len(results))
+	for _, result := range results {
+		ids = append(ids, result.ID)
+	}
+
+	shortIDs, err := h.bore.Clipboard().ShortIDs(c.Context, ids)
+	if err != nil {
+		return err
+	}
+
+	return h.tuiManager.RenderSearchResults(c.App.Writer, results, shortIDs)
+}
+
+// isTerminal reports whether the writer is an interactive terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return (info.Mode() & os.ModeCharDevice) != 0
+}
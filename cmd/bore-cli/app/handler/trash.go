@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+	"go.trulyao.dev/bore/v2/cmd/bore-cli/app/tui"
+	"go.trulyao.dev/bore/v2/pkg/events/aggregate"
+)
+
+// ListTrash lists the deleted items and collections that can still be restored.
+func (h *Handler) ListTrash(c *cli.Context) error {
+	entries, err := h.bore.Trash().List(c.Context)
+	if err != nil {
+		return err
+	}
+
+	reveal := c.Bool(FlagReveal)
+	if c.String(FlagFormat) == string(PasteFormatJSON) {
+		for _, entry := range entries {
+			if entry.Sensitive && !reveal {
+				entry.Content = []byte(tui.MaskedContent)
+			}
+		}
+		return h.tuiManager.RenderJSON(c.App.Writer, entries)
+	}
+
+	return h.tuiManager.RenderTrash(c.App.Writer, entries, reveal)
+}
+
+// RestoreFromTrash restores a deleted item or collection by ID or unique ID prefix.
+func (h *Handler) RestoreFromTrash(c *cli.Context) error {
+	if c.NArg() == 0 {
+		return cli.Exit("item or collection id is required", 1)
+	} else if c.NArg() > 1 {
+		return cli.Exit("too many arguments", 1)
+	}
+
+	result, err := h.bore.Trash().Restore(c.Context, c.Args().First())
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+
+	if result.Type == aggregate.AggregateTypeCollection {
+		_, _ = fmt.Fprintf(c.App.Writer, "Restored collection %s (%s)\n", result.Name, result.ID)
+		return nil
+	}
+
+	_, _ = fmt.Fprintln(c.App.Writer, "Restored item "+result.ID)
+	return nil
+}
+
+// EmptyTrash permanently removes everything in the trash, confirming with the user first unless --force is used.
+func (h *Handler) EmptyTrash(c *cli.Context) error {
+	if !c.Bool(FlagForce) {
+		var confirmation string
+		_, _ = fmt.Fprint(
+			c.App.Writer,
+			"Are you sure you want to permanently remove everything in the trash? This action cannot be undone. (y/N): ",
+		)
+		_, _ = fmt.Scanln(&confirmation)
+		if confirmation != "y" && confirmation != "Y" {
+			_, _ = fmt.Fprintln(c.App.Writer, "Aborted.")
+			return nil
+		}
+	}
+
+	removed, err := h.bore.Trash().Empty(c.Context)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(c.App.Writer, "Removed %d entry(ies) from the trash\n", removed)
+	return nil
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"strings"
+
+	"github.com/urfave/cli/v2"
+	"go.trulyao.dev/bore/v2"
+	"go.trulyao.dev/bore/v2/cmd/bore-cli/app/tui"
+	"go.trulyao.dev/bore/v2/database/models"
+	"go.trulyao.dev/bore/v2/pkg/mimetype"
+)
+
+// browserItemsLimit caps how many items the browser loads at once, the filter is the way to reach older items.
+const browserItemsLimit = 500
+
+// UI opens the interactive history browser.
+func (h *Handler) UI(c *cli.Context) error {
+	result, err := h.tuiManager.RunBrowser(tui.BrowserOptions{
+		LoadCollections: func() (models.Collections, error) {
+			return h.bore.Collections().List(c.Context, bore.ListCollectionsOptions{})
+		},
+		LoadItems: func(collectionID string, filter string) (models.Items, error) {
+			if strings.TrimSpace(filter) == "" {
+				//nolint:exhaustruct
+				result, err := h.bore.Clipboard().List(c.Context, bore.ListClipboardOptions{
+					CollectionID: collectionID,
+					PinnedFirst:  true,
+					Limit:        browserItemsLimit,
+				})
+				return result.Items, err
+			}
+
+			//nolint:exhaustruct
+			results, err := h.bore.Clipboard().Search(c.Context, bore.SearchOptions{
+				Query:        filter,
+				CollectionID: collectionID,
+				Limit:        browserItemsLimit,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			items := make(models.Items, 0, len(results))
+			for _, result := range results {
+				items = append(items, &result.Item)
+			}
+			return items, nil
+		},
+		Copy: func(item *models.Item) error {
+			system, err := h.bore.SystemClipboard()
+			if err != nil || !system.Available() {
+				return ErrClipboardNotAvailable
+			}
+
+			reader, err := h.bore.Clipboard().OpenContent(item)
+			if err != nil {
+				return err
+			}
+			defer func() { _ = reader.Close() }()
+
+			// Re-copying through bore bumps the item so it becomes the latest again.
+			_, err = h.bore.Clipboard().SetFrom(c.Context, reader, bore.SetClipboardOptions{
+				Passthrough:  true,
+				CollectionID: item.CollectionID.String,
+				Mimetype:     mimetype.MimeType(item.Mimetype),
+			})
+
+			// The item is already in the history, so a secret warning is not worth reporting, only a refusal is.
+			return err
+		},
+		TogglePin: func(item *models.Item) error {
+			if item.IsPinned() {
+				return h.bore.Clipboard().Unpin(c.Context, item.ID)
+			}
+			return h.bore.Clipboard().Pin(c.Context, item.ID)
+		},
+		Delete: func(item *models.Item) error {
+			return h.bore.Clipboard().Delete(c.Context, item.ID, bore.DeleteItemOptions{Force: false})
+		},
+		Reveal: c.Bool(FlagReveal),
+	})
+	if err != nil {
+		return cli.Exit("failed to run browser: "+err.Error(), 1)
+	}
+
+	if result.Paste == nil {
+		return nil
+	}
+
+	return h.writeItemContent(c.App.Writer, result.Paste)
+}
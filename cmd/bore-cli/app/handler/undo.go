@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+)
+
+// Undo reverts the most recent operation and prints what was reverted.
+func (h *Handler) Undo(c *cli.Context) error {
+	result, err := h.bore.Undo(c.Context)
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+
+	_, _ = fmt.Fprintln(c.App.Writer, "Undone: "+result.Description)
+	return nil
+}
+
+// Redo applies the most recently undone operation again and prints what was reapplied.
+func (h *Handler) Redo(c *cli.Context) error {
+	result, err := h.bore.Redo(c.Context)
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+
+	_, _ = fmt.Fprintln(c.App.Writer, "Redone: "+result.Description)
+	return nil
+}
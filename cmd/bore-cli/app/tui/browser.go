@@ -0,0 +1,327 @@
+package tui
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"go.trulyao.dev/bore/v2/database/models"
+)
+
+const browserHelp = "[::b]enter[::-] paste  [::b]y[::-] copy  [::b]p[::-] pin  [::b]d[::-] delete  [::b]/[::-] filter  [::b]tab[::-] switch pane  [::b]q[::-] quit"
+
+type (
+	// BrowserOptions provides the data source and actions for the history browser.
+	// The browser itself never touches the database, every action goes through these callbacks.
+	BrowserOptions struct {
+		// LoadCollections returns the collections shown in the left pane.
+		LoadCollections func() (models.Collections, error)
+
+		// LoadItems returns the items in the collection (all collections if empty) matching the filter.
+		LoadItems func(collectionID string, filter string) (models.Items, error)
+
+		// Copy copies the item to the system clipboard.
+		Copy func(item *models.Item) error
+
+		// TogglePin pins the item if it is not pinned, and unpins it otherwise.
+		TogglePin func(item *models.Item) error
+
+		// Delete deletes the item.
+		Delete func(item *models.Item) error
+
+		// Reveal shows the content of sensitive items instead of masking it.
+		Reveal bool
+	}
+
+	// BrowserResult is returned when the browser exits.
+	BrowserResult struct {
+		// Paste is the item the user chose to paste, nil if the browser was closed without picking one.
+		Paste *models.Item
+	}
+)
+
+type browser struct {
+	options     BrowserOptions
+	application *tview.Application
+
+	pages       *tview.Pages
+	collections *tview.List
+	items       *tview.List
+	preview     *tview.TextView
+	filter      *tview.InputField
+	status      *tview.TextView
+
+	collectionIDs []string
+	currentItems  models.Items
+	result        BrowserResult
+}
+
+// RunBrowser runs the full-screen history browser until the user quits or picks an item to paste.
+func (m *Manager) RunBrowser(options BrowserOptions) (BrowserResult, error) {
+	b := &browser{
+		options:       options,
+		application:   m.application,
+		pages:         tview.NewPages(),
+		collections:   tview.NewList(),
+		items:         tview.NewList(),
+		preview:       tview.NewTextView(),
+		filter:        tview.NewInputField(),
+		status:        tview.NewTextView(),
+		collectionIDs: []string{},
+		currentItems:  models.Items{},
+		result:        BrowserResult{Paste: nil},
+	}
+
+	b.setup()
+
+	if err := b.loadCollections(); err != nil {
+		return BrowserResult{}, err
+	}
+
+	if err := b.application.SetRoot(b.pages, true).SetFocus(b.items).Run(); err != nil {
+		return BrowserResult{}, err
+	}
+
+	return b.result, nil
+}
+
+func (b *browser) setup() {
+	b.collections.ShowSecondaryText(false).
+		SetHighlightFullLine(true).
+		SetBorder(true).
+		SetTitle(" Collections ")
+	b.collections.SetChangedFunc(func(int, string, string, rune) { b.reloadItems() })
+	b.collections.SetSelectedFunc(func(int, string, string, rune) {
+		b.application.SetFocus(b.items)
+	})
+
+	b.items.ShowSecondaryText(true).
+		SetHighlightFullLine(true).
+		SetBorder(true).
+		SetTitle(" Items ")
+	b.items.SetChangedFunc(func(index int, _ string, _ string, _ rune) { b.showPreview(index) })
+	b.items.SetSelectedFunc(func(index int, _ string, _ string, _ rune) {
+		if item := b.itemAt(index); item != nil {
+			b.result.Paste = item
+			b.application.Stop()
+		}
+	})
+	b.items.SetInputCapture(b.handleItemKeys)
+
+	b.preview.SetDynamicColors(false).
+		SetWrap(true).
+		SetBorder(true).
+		SetTitle(" Preview ")
+
+	b.filter.SetLabel("Filter: ").
+		SetFieldBackgroundColor(tcell.ColorDefault).
+		SetChangedFunc(func(string) { b.reloadItems() }).
+		SetDoneFunc(func(tcell.Key) { b.application.SetFocus(b.items) })
+
+	b.status.SetDynamicColors(true).SetText(browserHelp)
+
+	right := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(b.items, 0, 1, true).
+		AddItem(b.preview, 0, 1, false)
+
+	panes := tview.NewFlex().
+		AddItem(b.collections, 0, 1, false).
+		AddItem(right, 0, 3, true)
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(b.filter, 1, 0, false).
+		AddItem(panes, 0, 1, true).
+		AddItem(b.status, 1, 0, false)
+
+	b.pages.AddPage("main", layout, true, true)
+
+	b.application.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		// Let the filter and the confirmation dialog receive every key while they are active.
+		if b.filter.HasFocus() || b.pages.HasPage("confirm") {
+			return event
+		}
+
+		switch {
+		case event.Key() == tcell.KeyTab || event.Key() == tcell.KeyBacktab:
+			if b.collections.HasFocus() {
+				b.application.SetFocus(b.items)
+			} else if b.items.HasFocus() {
+				b.application.SetFocus(b.collections)
+			}
+			return nil
+
+		case event.Rune() == '/':
+			b.application.SetFocus(b.filter)
+			return nil
+
+		case event.Rune() == 'q' || event.Key() == tcell.KeyEscape:
+			b.application.Stop()
+			return nil
+		}
+
+		return event
+	})
+}
+
+func (b *browser) handleItemKeys(event *tcell.EventKey) *tcell.EventKey {
+	item := b.itemAt(b.items.GetCurrentItem())
+	if item == nil {
+		return event
+	}
+
+	switch {
+	case event.Rune() == 'y':
+		if err := b.options.Copy(item); err != nil {
+			b.setStatus("[red]failed to copy: " + tview.Escape(err.Error()))
+		} else {
+			b.setStatus("[green]copied " + item.ID + " to the system clipboard")
+		}
+		return nil
+
+	case event.Rune() == 'p':
+		if err := b.options.TogglePin(item); err != nil {
+			b.setStatus("[red]failed to toggle pin: " + tview.Escape(err.Error()))
+		} else {
+			b.reloadItems()
+		}
+		return nil
+
+	case event.Rune() == 'd' || event.Key() == tcell.KeyDelete:
+		b.confirmDelete(item)
+		return nil
+	}
+
+	return event
+}
+
+func (b *browser) confirmDelete(item *models.Item) {
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("Delete item %s?", item.ID)).
+		AddButtons([]string{"Delete", "Cancel"}).
+		SetDoneFunc(func(_ int, label string) {
+			b.pages.RemovePage("confirm")
+			b.application.SetFocus(b.items)
+
+			if label != "Delete" {
+				return
+			}
+
+			if err := b.options.Delete(item); err != nil {
+				b.setStatus("[red]failed to delete: " + tview.Escape(err.Error()))
+				return
+			}
+
+			b.setStatus("[green]deleted " + item.ID)
+			b.reloadItems()
+		})
+
+	b.pages.AddPage("confirm", modal, false, true)
+	b.application.SetFocus(modal)
+}
+
+func (b *browser) loadCollections() error {
+	collections, err := b.options.LoadCollections()
+	if err != nil {
+		return err
+	}
+
+	b.collections.Clear()
+	b.collectionIDs = b.collectionIDs[:0]
+
+	b.collectionIDs = append(b.collectionIDs, "")
+	b.collections.AddItem("All", "", 0, nil)
+
+	for _, collection := range collections {
+		b.collectionIDs = append(b.collectionIDs, collection.ID)
+		b.collections.AddItem(
+			fmt.Sprintf("%s (%d)", tview.Escape(collection.Name), collection.ItemsCount),
+			"",
+			0,
+			nil,
+		)
+	}
+
+	b.reloadItems()
+	return nil
+}
+
+func (b *browser) reloadItems() {
+	collectionID := ""
+	if index := b.collections.GetCurrentItem(); index >= 0 && index < len(b.collectionIDs) {
+		collectionID = b.collectionIDs[index]
+	}
+
+	items, err := b.options.LoadItems(collectionID, b.filter.GetText())
+	if err != nil {
+		b.setStatus("[red]failed to load items: " + tview.Escape(err.Error()))
+		return
+	}
+
+	previous := b.items.GetCurrentItem()
+
+	b.currentItems = items
+	b.items.Clear()
+	for _, item := range items {
+		b.items.AddItem(
+			pinMarker(item)+tview.Escape(ItemPreview(item, previewLength, b.masked(item))),
+			tview.Escape(fmt.Sprintf(
+				"%s  %s  %s  %s",
+				item.ID,
+				item.Mimetype,
+				FormatSize(int(item.Size())),
+				item.CreatedAt.Local().Format("Jan 02 2006 15:04"),
+			)),
+			0,
+			nil,
+		)
+	}
+
+	if previous > 0 && previous < len(items) {
+		b.items.SetCurrentItem(previous)
+	}
+
+	b.showPreview(b.items.GetCurrentItem())
+}
+
+func (b *browser) showPreview(index int) {
+	item := b.itemAt(index)
+	if item == nil {
+		b.preview.SetText("")
+		return
+	}
+
+	if b.masked(item) {
+		b.preview.SetText(MaskedContent)
+		return
+	}
+
+	if item.InBlobStore() {
+		b.preview.SetText(blobPreview(item.ContentSize))
+		return
+	}
+
+	if !utf8.Valid(item.Content) {
+		b.preview.SetText(fmt.Sprintf("[binary content, %s]", FormatSize(len(item.Content))))
+		return
+	}
+
+	b.preview.SetText(string(item.Content)).ScrollToBeginning()
+}
+
+// masked reports whether the content of the item should be hidden.
+func (b *browser) masked(item *models.Item) bool {
+	return item.Sensitive && !b.options.Reveal
+}
+
+func (b *browser) itemAt(index int) *models.Item {
+	if index < 0 || index >= len(b.currentItems) {
+		return nil
+	}
+
+	return b.currentItems[index]
+}
+
+func (b *browser) setStatus(message string) {
+	b.status.SetText(message + "  [-]" + browserHelp)
+}
@@ -12,6 +12,7 @@ import (
 func (m *Manager) RenderCollectionsList(
 	output io.Writer,
 	collections models.Collections,
+	shortIDs map[string]string,
 ) error {
 	writer := tabwriter.NewWriter(output, 0, 8, 4, ' ', 0)
 
@@ -22,7 +23,7 @@ func (m *Manager) RenderCollectionsList(
 		_, _ = fmt.Fprintf(
 			writer,
 			"%s\t%s\t%s\t%s\n",
-			collection.ID,
+			shortID(shortIDs, collection.ID),
 			collection.Name,
 			createdAt,
 			strconv.Itoa(collection.ItemsCount),
@@ -0,0 +1,183 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"unicode"
+	"unicode/utf8"
+
+	"go.trulyao.dev/bore/v2/database/models"
+)
+
+const previewLength = 60
+
+// MaskedContent is shown in place of the content of sensitive items.
+const MaskedContent = "••••"
+
+// RenderItemsList renders items as a table, showing the short form of every ID found in shortIDs.
+// The content of sensitive items is masked unless reveal is set.
+func (m *Manager) RenderItemsList(
+	output io.Writer,
+	items models.Items,
+	shortIDs map[string]string,
+	reveal bool,
+) error {
+	writer := tabwriter.NewWriter(output, 0, 8, 4, ' ', 0)
+
+	_, _ = fmt.Fprintln(writer, "ID\tCOLLECTION\tMIMETYPE\tSIZE\tPREVIEW")
+
+	for _, item := range items {
+		collection := "-"
+		if item.Collection != nil {
+			collection = item.Collection.Name
+		}
+
+		_, _ = fmt.Fprintf(
+			writer,
+			"%s\t%s\t%s\t%s\t%s\n",
+			shortID(shortIDs, item.ID),
+			collection,
+			item.Mimetype,
+			FormatSize(int(item.Size())),
+			pinMarker(item)+ItemPreview(item, previewLength, item.Sensitive && !reveal),
+		)
+	}
+
+	return writer.Flush()
+}
+
+// shortID returns the short form of the ID if there is one, and the full ID otherwise.
+func shortID(shortIDs map[string]string, id string) string {
+	if short, ok := shortIDs[id]; ok {
+		return short
+	}
+	return id
+}
+
+// pinMarker returns the prefix shown before pinned items in listings.
+func pinMarker(item *models.Item) string {
+	if item.IsPinned() {
+		return "[pinned] "
+	}
+	return ""
+}
+
+// SensitivePreview returns the preview of the content, or MaskedContent if masked is set.
+func SensitivePreview(content []byte, maxLength int, masked bool) string {
+	if masked {
+		return MaskedContent
+	}
+	return Preview(content, maxLength)
+}
+
+// ItemPreview is SensitivePreview for an item, content kept in the blob store is only described since it is not loaded for listings.
+func ItemPreview(item *models.Item, maxLength int, masked bool) string {
+	if item.InBlobStore() && !masked {
+		return blobPreview(item.ContentSize)
+	}
+	return SensitivePreview(item.Content, maxLength, masked)
+}
+
+// blobPreview describes content of the given size kept in the blob store.
+func blobPreview(size int64) string {
+	return "[stored on disk, " + FormatSize(int(size)) + "]"
+}
+
+// Preview returns the first line of the content, truncated to at most maxLength runes.
+// Content that is not valid UTF-8 is summarised instead of being printed.
+func Preview(content []byte, maxLength int) string {
+	if !utf8.Valid(content) {
+		return "[binary " + FormatSize(len(content)) + "]"
+	}
+
+	text := strings.TrimSpace(string(content))
+	line, _, multiline := strings.Cut(text, "\n")
+	line = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, line)
+
+	if utf8.RuneCountInString(line) > maxLength {
+		return string([]rune(line)[:maxLength-1]) + "…"
+	}
+
+	if multiline {
+		return line + " …"
+	}
+
+	return line
+}
+
+// FormatSize formats a size in bytes using binary units.
+func FormatSize(size int) string {
+	const unit = 1024
+	if size < unit {
+		return strconv.Itoa(size) + " B"
+	}
+
+	div, exp := unit, 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+func (m *Manager) RenderSearchResults(
+	output io.Writer,
+	results models.ItemSearchResults,
+	shortIDs map[string]string,
+) error {
+	writer := tabwriter.NewWriter(output, 0, 8, 4, ' ', 0)
+
+	_, _ = fmt.Fprintln(writer, "ID\tCOLLECTION\tSNIPPET")
+
+	for _, result := range results {
+		collection := "-"
+		if result.Collection != nil {
+			collection = result.Collection.Name
+		}
+
+		_, _ = fmt.Fprintf(writer, "%s\t%s\t%s\n", shortID(shortIDs, result.ID), collection, result.Snippet)
+	}
+
+	return writer.Flush()
+}
+
+// PrunedItem is a row in the output of RenderPrunedItems.
+type PrunedItem struct {
+	ID             string
+	CollectionName string
+	Size           int64
+	Reason         string
+}
+
+func (m *Manager) RenderPrunedItems(output io.Writer, items []PrunedItem) error {
+	writer := tabwriter.NewWriter(output, 0, 8, 4, ' ', 0)
+
+	_, _ = fmt.Fprintln(writer, "ID\tCOLLECTION\tSIZE\tREASON")
+
+	for _, item := range items {
+		collection := item.CollectionName
+		if collection == "" {
+			collection = "-"
+		}
+
+		_, _ = fmt.Fprintf(
+			writer,
+			"%s\t%s\t%s\t%s\n",
+			item.ID,
+			collection,
+			FormatSize(int(item.Size)),
+			item.Reason,
+		)
+	}
+
+	return writer.Flush()
+}
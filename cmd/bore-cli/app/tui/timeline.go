@@ -0,0 +1,73 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"go.trulyao.dev/bore/v2/pkg/events"
+)
+
+const timelineTimeFormat = "Jan 02 2006 15:04:05"
+
+// RenderItemTimeline renders a summary of the item's history followed by every event in its stream.
+// The preview is masked if the item is sensitive, unless reveal is set.
+func (m *Manager) RenderItemTimeline(output io.Writer, timeline *events.ItemTimeline, reveal bool) error {
+	writer := tabwriter.NewWriter(output, 0, 8, 4, ' ', 0)
+
+	lastCopied := timeline.CreatedAt
+	if len(timeline.CopiedAt) > 0 {
+		lastCopied = timeline.CopiedAt[len(timeline.CopiedAt)-1]
+	}
+
+	status := "active"
+	if timeline.Deleted {
+		status = "deleted"
+		if timeline.Purged {
+			status = "purged"
+		}
+		if !timeline.DeletedAt.IsZero() {
+			status += " at " + formatTimelineTime(timeline.DeletedAt)
+		}
+	}
+
+	_, _ = fmt.Fprintf(writer, "ID:\t%s\n", timeline.ItemID)
+	_, _ = fmt.Fprintf(writer, "CREATED AT:\t%s\n", formatTimelineTime(timeline.CreatedAt))
+	_, _ = fmt.Fprintf(writer, "LAST COPIED AT:\t%s\n", formatTimelineTime(lastCopied))
+	_, _ = fmt.Fprintf(writer, "COPIES:\t%s\n", strconv.Itoa(timeline.CopyCount))
+	preview := SensitivePreview(timeline.Content, previewLength, timeline.Sensitive && !reveal)
+	if timeline.BlobHash != "" && preview != MaskedContent {
+		preview = blobPreview(timeline.Size)
+	}
+
+	_, _ = fmt.Fprintf(writer, "STATUS:\t%s\n", status)
+	_, _ = fmt.Fprintf(writer, "PREVIEW:\t%s\n", preview)
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintln(output)
+
+	writer = tabwriter.NewWriter(output, 0, 8, 4, ' ', 0)
+	_, _ = fmt.Fprintln(writer, "VERSION\tEVENT\tOCCURRED AT")
+	for _, entry := range timeline.Entries {
+		_, _ = fmt.Fprintf(
+			writer,
+			"%d\t%s\t%s\n",
+			entry.Version,
+			entry.Action,
+			formatTimelineTime(entry.OccurredAt),
+		)
+	}
+
+	return writer.Flush()
+}
+
+func formatTimelineTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format(timelineTimeFormat)
+}
@@ -0,0 +1,40 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"go.trulyao.dev/bore/v2/database/models"
+	"go.trulyao.dev/bore/v2/pkg/events/aggregate"
+)
+
+// RenderTrash renders trashed items and collections as a table, masking the content of sensitive items unless reveal is set.
+func (m *Manager) RenderTrash(output io.Writer, entries models.TrashEntries, reveal bool) error {
+	writer := tabwriter.NewWriter(output, 0, 8, 4, ' ', 0)
+
+	_, _ = fmt.Fprintln(writer, "ID\tTYPE\tDELETED AT\tSIZE\tPREVIEW")
+
+	for _, entry := range entries {
+		size, preview := FormatSize(int(entry.Size())), SensitivePreview(entry.Content, previewLength, entry.Sensitive && !reveal)
+		if entry.BlobHash != "" && preview != MaskedContent {
+			preview = blobPreview(entry.ContentSize)
+		}
+		if entry.AggregateType == aggregate.AggregateTypeCollection.String() {
+			size, preview = "-", entry.Name.String+" ("+strconv.Itoa(entry.ItemsCount)+" item(s))"
+		}
+
+		_, _ = fmt.Fprintf(
+			writer,
+			"%s\t%s\t%s\t%s\t%s\n",
+			entry.AggregateID,
+			entry.AggregateType,
+			entry.DeletedAt.Local().Format("Jan 02 2006 15:04"),
+			size,
+			preview,
+		)
+	}
+
+	return writer.Flush()
+}
@@ -2,6 +2,9 @@ package bore
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
 
 	"go.trulyao.dev/bore/v2/database/models"
 	"go.trulyao.dev/bore/v2/database/repository"
@@ -26,26 +29,74 @@ type (
 		Name string
 		ID   string
 	}
+
+	DeleteCollectionOptions struct {
+		Force bool // Whether to delete the collection even if it contains pinned items.
+	}
+
+	ResolveCollectionOptions struct {
+		Create bool // Whether to create a collection with the identifier as its name if none is found.
+	}
 )
 
+// CollectionResolver resolves a collection name, ID or unique ID prefix to a collection.
+type CollectionResolver interface {
+	Resolve(
+		ctx context.Context,
+		identifier string,
+		options ResolveCollectionOptions,
+	) (*models.Collection, error)
+}
+
+var _ CollectionResolver = (*collectionNamespace)(nil)
+
+// Get returns the collection with the given name, ID or unique ID prefix.
 func (c *collectionNamespace) Get(
 	ctx context.Context,
 	identifier string,
 ) (*models.Collection, error) {
+	return c.Resolve(ctx, identifier, ResolveCollectionOptions{Create: false})
+}
+
+// Resolve returns the collection with the given name, ID or unique ID prefix.
+// This is the single place where user-provided collection identifiers are turned into collections.
+func (c *collectionNamespace) Resolve(
+	ctx context.Context,
+	identifier string,
+	options ResolveCollectionOptions,
+) (*models.Collection, error) {
+	identifier = strings.TrimSpace(identifier)
+	if identifier == "" {
+		return nil, errs.New("collection name or id is required")
+	}
+
 	collection, err := c.repository.Collections().
 		FindOne(ctx, repository.CollectionLookupOptions{
 			Identifier: identifier,
-			Name:       "",
+			Name:       identifier,
 		})
 	if err != nil {
 		return nil, errs.New("failed to fetch collection").WithError(err)
 	}
 
-	if collection == nil {
-		return nil, errs.New("collection not found")
+	if collection != nil {
+		return collection, nil
 	}
 
-	return collection, nil
+	if !options.Create {
+		return nil, errs.ErrCollectionNotFound.WithError(errs.New(strconv.Quote(identifier)))
+	}
+
+	result, err := c.Create(ctx, CreateCollectionOptions{
+		Name:                 identifier,
+		AppendSuffixIfExists: false,
+	})
+	if err != nil {
+		return nil, errs.New(fmt.Sprintf("failed to create collection %q", identifier)).
+			WithError(err)
+	}
+
+	return c.repository.Collections().FindById(ctx, result.ID)
 }
 
 func (c *collectionNamespace) Create(
@@ -81,7 +132,22 @@ func (c *collectionNamespace) Create(
 			WithError(err)
 	}
 
-	if _, _, err = c.manager.Apply(ctx, event); err != nil {
+	undo, err := newEvent(
+		aggregate.AggregateTypeCollection,
+		event.Aggregate.ID(),
+		&payload.DeleteCollection{},
+	)
+	if err != nil {
+		return CreateCollectionResult{}, err
+	}
+
+	err = c.applyUndoable(
+		ctx,
+		"create collection "+name,
+		[]*events.Event{event},
+		[]*events.Event{undo},
+	)
+	if err != nil {
 		return CreateCollectionResult{}, errs.New("failed to apply collection creation event").
 			WithError(err)
 	}
@@ -101,31 +167,63 @@ func (c *collectionNamespace) Create(
 }
 
 // Delete deletes a collection and all its associated items.
-func (c *collectionNamespace) Delete(ctx context.Context, identifier string) error {
-	existingCollection, err := c.repository.Collections().
-		FindOne(ctx, repository.CollectionLookupOptions{
-			Identifier: identifier,
-			Name:       "",
-		})
+// Collections containing pinned items are only deleted if forced.
+func (c *collectionNamespace) Delete(
+	ctx context.Context,
+	identifier string,
+	options DeleteCollectionOptions,
+) error {
+	existingCollection, err := c.Get(ctx, identifier)
 	if err != nil {
-		return errs.New("failed to check existing collection").WithError(err)
+		return err
 	}
 
-	if existingCollection == nil {
-		return errs.New("collection not found")
+	if !options.Force {
+		pinned, err := c.repository.Items().CountPinned(ctx, existingCollection.ID)
+		if err != nil {
+			return errs.New("failed to count pinned items").WithError(err)
+		}
+
+		if pinned > 0 {
+			return errs.New(fmt.Sprintf(
+				"collection contains %d pinned item(s), unpin them first or force the deletion",
+				pinned,
+			))
+		}
 	}
 
-	agg, err := aggregate.WithID(aggregate.AggregateTypeCollection, identifier)
+	event, err := newEvent(
+		aggregate.AggregateTypeCollection,
+		existingCollection.ID,
+		&payload.DeleteCollection{},
+	)
 	if err != nil {
-		return errs.New("failed to create aggregate for deletion event").WithError(err)
+		return err
 	}
 
-	event, err := events.New(agg, &payload.DeleteCollection{})
+	undo, err := newEvent(
+		aggregate.AggregateTypeCollection,
+		existingCollection.ID,
+		&payload.RestoreCollection{},
+	)
 	if err != nil {
-		return errs.New("failed to create collection deletion event").WithError(err)
+		return err
 	}
 
-	if _, _, err = c.manager.Apply(ctx, event); err != nil {
+	//nolint:exhaustruct
+	items, err := c.repository.Items().FindAll(ctx, repository.FindItemsOptions{
+		CollectionID: existingCollection.ID,
+	})
+	if err != nil {
+		return errs.New("failed to list items in collection").WithError(err)
+	}
+
+	description := fmt.Sprintf(
+		"delete collection %s (%d item(s))",
+		existingCollection.Name,
+		len(items),
+	)
+	if err := c.applyUndoable(ctx, description, []*events.Event{event}, []*events.Event{undo}); err != nil {
 		return errs.New("failed to apply collection deletion event").WithError(err)
 	}
 
@@ -148,17 +246,36 @@ func (c *collectionNamespace) Rename(ctx context.Context, identifier, newName st
 		return errs.New("collection with the same name already exists")
 	}
 
-	agg, err := aggregate.WithID(aggregate.AggregateTypeCollection, identifier)
+	collection, err := c.Get(ctx, identifier)
 	if err != nil {
-		return errs.New("failed to create aggregate for rename event").WithError(err)
+		return err
 	}
 
-	event, err := events.New(agg, &payload.RenameCollection{NewName: newName})
+	event, err := newEvent(
+		aggregate.AggregateTypeCollection,
+		collection.ID,
+		&payload.RenameCollection{NewName: newName},
+	)
+	if err != nil {
+		return err
+	}
+
+	undo, err := newEvent(
+		aggregate.AggregateTypeCollection,
+		collection.ID,
+		&payload.RenameCollection{NewName: collection.Name},
+	)
 	if err != nil {
-		return errs.New("failed to create collection rename event").WithError(err)
+		return err
 	}
 
-	if _, _, err = c.manager.Apply(ctx, event); err != nil {
+	err = c.applyUndoable(
+		ctx,
+		"rename collection "+collection.Name+" to "+newName,
+		[]*events.Event{event},
+		[]*events.Event{undo},
+	)
+	if err != nil {
 		return errs.New("failed to apply collection rename event").WithError(err)
 	}
 
@@ -187,3 +304,13 @@ func (c *collectionNamespace) List(
 
 	return collections, nil
 }
+
+// ShortIDs returns the shortest unique prefix of each of the given collection IDs, for display.
+func (c *collectionNamespace) ShortIDs(ctx context.Context, ids []string) (map[string]string, error) {
+	prefixes, err := c.repository.Collections().ShortestPrefixes(ctx, ids)
+	if err != nil {
+		return nil, errs.New("failed to compute short collection ids").WithError(err)
+	}
+
+	return prefixes, nil
+}
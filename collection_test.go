@@ -0,0 +1,44 @@
+package bore_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.trulyao.dev/bore/v2"
+	"go.trulyao.dev/bore/v2/pkg/errs"
+)
+
+func Test_ResolveCollection(t *testing.T) {
+	ctx := context.Background()
+	b := newBore(t)
+	workID := createCollection(t, b, "work")
+
+	for _, identifier := range []string{"work", workID, workID[:len(workID)-4]} {
+		collection, err := b.Collections().Resolve(ctx, identifier, bore.ResolveCollectionOptions{})
+		if err != nil {
+			t.Errorf("%q: expected no error, got %v", identifier, err)
+		} else if collection.ID != workID {
+			t.Errorf("%q: expected %s, got %s", identifier, workID, collection.ID)
+		}
+	}
+
+	_, err := b.Collections().Resolve(ctx, "missing", bore.ResolveCollectionOptions{})
+	if !errors.Is(err, errs.ErrCollectionNotFound) {
+		t.Errorf("expected ErrCollectionNotFound, got %v", err)
+	}
+
+	if err == nil || !strings.Contains(err.Error(), `"missing"`) {
+		t.Errorf("expected the error to name the collection, got %v", err)
+	}
+
+	created, err := b.Collections().Resolve(ctx, "missing", bore.ResolveCollectionOptions{Create: true})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if created.Name != "missing" {
+		t.Errorf("expected the collection to be created, got %+v", created)
+	}
+}
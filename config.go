@@ -2,10 +2,16 @@ package bore
 
 import (
 	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
-	"github.com/oklog/ulid/v2"
+	"go.trulyao.dev/bore/v2/database/models"
 	"go.trulyao.dev/bore/v2/pkg/errs"
+	"go.trulyao.dev/bore/v2/pkg/secrets"
 )
 
 // NOTE: where and show the configuration is stored is determined by the application layer.
@@ -18,6 +24,103 @@ type Config struct {
 
 	// DefaultCollection is the name of the default collection to use when none is specified
 	DefaultCollection string `toml:"default_collection" json:"default_collection"`
+
+	// Retention limits how much history is kept in every collection, it is disabled by default.
+	Retention RetentionPolicy `toml:"retention,omitempty" json:"retention"`
+
+	// CollectionRetention overrides the retention policy for specific collections, keyed by collection name or ID.
+	CollectionRetention map[string]RetentionPolicy `toml:"collection_retention,omitempty" json:"collection_retention"`
+
+	// TrashRetention is how long deleted items and collections are kept in the trash, e.g. "168h".
+	// Zero uses DefaultTrashRetention and a negative value keeps them until the trash is emptied.
+	TrashRetention time.Duration `toml:"trash_retention,omitzero" json:"trash_retention"`
+
+	// SensitiveTTL is how long items copied as sensitive are kept when no expiry is given, e.g. "5m".
+	// Zero uses DefaultSensitiveTTL and a negative value keeps them until they are deleted.
+	SensitiveTTL time.Duration `toml:"sensitive_ttl,omitzero" json:"sensitive_ttl"`
+
+	// BlobThreshold is the size in bytes above which copied content is kept as a file in the data directory instead of in the database.
+	// Zero uses DefaultBlobThreshold and a negative value keeps all content in the database, as does encrypting the data.
+	BlobThreshold int64 `toml:"blob_threshold,omitzero" json:"blob_threshold"`
+
+	// SecretDetection configures how copied content that looks like a secret is handled.
+	SecretDetection SecretDetectionConfig `toml:"secret_detection,omitempty" json:"secret_detection"`
+
+	// Encryption configures where the encryption key comes from once the data has been encrypted with `bore key init`.
+	Encryption EncryptionConfig `toml:"encryption,omitempty" json:"encryption"`
+}
+
+// DefaultPassphraseEnv is the environment variable the passphrase is read from when none is configured.
+const DefaultPassphraseEnv = "BORE_PASSPHRASE"
+
+// EncryptionConfig describes where the encryption key is read from.
+// Whether the data is encrypted, and with which kind of key, is recorded in the database itself, so these settings are ignored until encryption is enabled.
+type EncryptionConfig struct {
+	// KeyFile is the path to a file holding a base64-encoded 32-byte key.
+	KeyFile string `toml:"key_file,omitempty" json:"key_file"`
+
+	// PassphraseEnv is the environment variable the passphrase is read from, DefaultPassphraseEnv if empty.
+	PassphraseEnv string `toml:"passphrase_env,omitempty" json:"passphrase_env"`
+
+	// Passphrase takes precedence over PassphraseEnv, it is never written to the configuration file.
+	Passphrase string `toml:"-" json:"-"`
+}
+
+const (
+	// DefaultTrashRetention is used when no trash retention is configured.
+	DefaultTrashRetention = 30 * 24 * time.Hour
+
+	// DefaultSensitiveTTL is used when no expiry is configured for sensitive items.
+	DefaultSensitiveTTL = 10 * time.Minute
+
+	// DefaultBlobThreshold is used when no blob threshold is configured.
+	DefaultBlobThreshold int64 = 1 << 20
+)
+
+// SecretPolicy is what happens when copied content looks like a secret.
+type SecretPolicy string
+
+const (
+	// SecretPolicyWarn copies the content and reports the match.
+	SecretPolicyWarn SecretPolicy = "warn"
+	// SecretPolicyMarkSensitive copies the content as a sensitive item, see SetClipboardOptions.Sensitive.
+	SecretPolicyMarkSensitive SecretPolicy = "mark-sensitive"
+	// SecretPolicyRefuse does not copy the content at all.
+	SecretPolicyRefuse SecretPolicy = "refuse"
+	// SecretPolicyOff disables the detection.
+	SecretPolicyOff SecretPolicy = "off"
+
+	// DefaultSecretPolicy is used when no policy is configured.
+	DefaultSecretPolicy = SecretPolicyWarn
+)
+
+// SecretDetectionConfig configures the secret detection run on every copy.
+type SecretDetectionConfig struct {
+	// Policy applies to every collection without an override, DefaultSecretPolicy if empty.
+	Policy SecretPolicy `toml:"policy,omitempty" json:"policy"`
+
+	// CollectionPolicy overrides the policy for specific collections, keyed by collection name or ID.
+	CollectionPolicy map[string]SecretPolicy `toml:"collection_policy,omitempty" json:"collection_policy"`
+
+	// Rules are checked after the built-in rules, keyed by rule name with a regular expression as the value.
+	Rules map[string]string `toml:"rules,omitempty" json:"rules"`
+
+	// DisableBuiltinRules only checks the configured rules, which also disables the check for high-entropy strings.
+	DisableBuiltinRules bool `toml:"disable_builtin_rules,omitempty" json:"disable_builtin_rules"`
+}
+
+// RetentionPolicy describes how much history to keep in a collection.
+// Items removed by a policy are deleted through regular delete events, and pinned items are never removed (or counted).
+// A zero value means "no limit", or "inherit from the global policy" in a per-collection override, where a negative value disables the limit instead.
+type RetentionPolicy struct {
+	// MaxItems is the maximum number of items to keep.
+	MaxItems int `toml:"max_items,omitzero" json:"max_items"`
+
+	// MaxAge is the maximum time since an item was last copied, e.g. "720h".
+	MaxAge time.Duration `toml:"max_age,omitzero" json:"max_age"`
+
+	// MaxBytes is the maximum total size of the contents of all items.
+	MaxBytes int64 `toml:"max_bytes,omitzero" json:"max_bytes"`
 }
 
 // DefaultConfig returns the default configuration for the bore application.
@@ -29,16 +132,178 @@ func DefaultConfig() Config {
 	}
 }
 
-func (c *Config) SetDefaultCollection(identifier string) error {
-	id, err := ulid.Parse(identifier)
+// SetDefaultCollection resolves the collection name, ID or unique ID prefix and stores its full ID as the default collection.
+func (c *Config) SetDefaultCollection(
+	ctx context.Context,
+	resolver CollectionResolver,
+	identifier string,
+	options ResolveCollectionOptions,
+) (*models.Collection, error) {
+	collection, err := resolver.Resolve(ctx, identifier, options)
 	if err != nil {
-		return errs.Wrap(err, "invalid collection identifier")
+		return nil, errs.Wrap(err, "invalid default collection")
+	}
+
+	c.DefaultCollection = collection.ID
+	return collection, nil
+}
+
+// RetentionFor returns the effective retention policy for a collection, applying any override configured for its ID or name.
+// Uncategorized items (empty ID and name) always use the global policy.
+func (c *Config) RetentionFor(collectionID, collectionName string) RetentionPolicy {
+	policy := c.Retention
+
+	override, ok := c.CollectionRetention[collectionID]
+	if !ok && collectionName != "" {
+		for key, value := range c.CollectionRetention {
+			if strings.EqualFold(strings.TrimSpace(key), collectionName) {
+				override, ok = value, true
+				break
+			}
+		}
+	}
+
+	if !ok || collectionID == "" {
+		return policy
+	}
+
+	if override.MaxItems != 0 {
+		policy.MaxItems = max(override.MaxItems, 0)
+	}
+	if override.MaxAge != 0 {
+		policy.MaxAge = max(override.MaxAge, 0)
+	}
+	if override.MaxBytes != 0 {
+		policy.MaxBytes = max(override.MaxBytes, 0)
+	}
+
+	return policy
+}
+
+// TrashRetentionPeriod returns the effective trash retention, or zero if trashed entries never expire.
+func (c *Config) TrashRetentionPeriod() time.Duration {
+	switch {
+	case c.TrashRetention < 0:
+		return 0
+	case c.TrashRetention == 0:
+		return DefaultTrashRetention
+	default:
+		return c.TrashRetention
+	}
+}
+
+// SensitiveTTLPeriod returns the effective expiry of sensitive items, or zero if they do not expire.
+func (c *Config) SensitiveTTLPeriod() time.Duration {
+	switch {
+	case c.SensitiveTTL < 0:
+		return 0
+	case c.SensitiveTTL == 0:
+		return DefaultSensitiveTTL
+	default:
+		return c.SensitiveTTL
+	}
+}
+
+// BlobThresholdBytes returns the effective blob threshold, or zero if content is never kept as a file.
+func (c *Config) BlobThresholdBytes() int64 {
+	switch {
+	case c.BlobThreshold < 0:
+		return 0
+	case c.BlobThreshold == 0:
+		return DefaultBlobThreshold
+	default:
+		return c.BlobThreshold
+	}
+}
+
+// PolicyFor returns the effective secret policy for a collection, applying any override configured for its ID or name.
+// Uncategorized items (empty ID and name) always use the global policy.
+func (s SecretDetectionConfig) PolicyFor(collectionID, collectionName string) SecretPolicy {
+	policy := s.Policy
+	if collectionID != "" {
+		override, ok := s.CollectionPolicy[collectionID]
+		if !ok && collectionName != "" {
+			for key, value := range s.CollectionPolicy {
+				if strings.EqualFold(strings.TrimSpace(key), collectionName) {
+					override, ok = value, true
+					break
+				}
+			}
+		}
+
+		if ok && override != "" {
+			policy = override
+		}
+	}
+
+	if policy == "" {
+		return DefaultSecretPolicy
+	}
+
+	return policy
+}
+
+// Detector builds the secret detector for the configured rules.
+func (s SecretDetectionConfig) Detector() (*secrets.Detector, error) {
+	if err := s.validatePolicies(); err != nil {
+		return nil, err
+	}
+
+	rules, err := secrets.ParseRules(s.Rules)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.DisableBuiltinRules {
+		return secrets.New(rules, false), nil
+	}
+
+	return secrets.New(append(secrets.Builtin(), rules...), true), nil
+}
+
+func (s SecretDetectionConfig) validatePolicies() error {
+	policies := []SecretPolicy{s.Policy}
+	for _, policy := range s.CollectionPolicy {
+		policies = append(policies, policy)
+	}
+
+	for _, policy := range policies {
+		switch policy {
+		case "", SecretPolicyWarn, SecretPolicyMarkSensitive, SecretPolicyRefuse, SecretPolicyOff:
+		default:
+			return errs.New(fmt.Sprintf(
+				"invalid secret policy %q, expected one of warn, mark-sensitive, refuse or off",
+				policy,
+			))
+		}
 	}
 
-	c.DefaultCollection = id.String()
 	return nil
 }
 
+// PassphraseEnvName returns the name of the environment variable the passphrase is read from.
+func (e EncryptionConfig) PassphraseEnvName() string {
+	if name := strings.TrimSpace(e.PassphraseEnv); name != "" {
+		return name
+	}
+
+	return DefaultPassphraseEnv
+}
+
+// ReadPassphrase returns the configured passphrase, or the value of the passphrase environment variable.
+func (e EncryptionConfig) ReadPassphrase() string {
+	if e.Passphrase != "" {
+		return e.Passphrase
+	}
+
+	return os.Getenv(e.PassphraseEnvName())
+}
+
+// IsZero reports whether the policy has no limits.
+func (p RetentionPolicy) IsZero() bool {
+	return p.MaxItems <= 0 && p.MaxAge <= 0 && p.MaxBytes <= 0
+}
+
 // FromBytes reads the configuration from a byte slice and populates the Config struct.
 func (c *Config) FromBytes(data []byte) (*Config, error) {
 	decoder := toml.NewDecoder(bytes.NewReader(data))
@@ -2,6 +2,7 @@ package bore_test
 
 import (
 	"testing"
+	"time"
 
 	"go.trulyao.dev/bore/v2"
 )
@@ -50,3 +51,145 @@ default_collection = ""
 		t.Errorf("expected TOML output to be:\n%s\nbut got:\n%s", expected, string(tomlData))
 	}
 }
+
+func Test_RetentionFor(t *testing.T) {
+	config := &bore.Config{}
+
+	data := `
+	data_dir = "~/.local/share/bore"
+
+	[retention]
+	max_items = 100
+	max_age = "720h"
+
+	[collection_retention.Work]
+	max_items = 10
+	max_age = "-1s"
+	`
+
+	if _, err := config.FromBytes([]byte(data)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	global := config.RetentionFor("", "")
+	if global.MaxItems != 100 || global.MaxAge != 720*time.Hour {
+		t.Errorf("expected the global policy for uncategorized items, got %+v", global)
+	}
+
+	work := config.RetentionFor("01K0000000000000000000000", "work")
+	if work.MaxItems != 10 {
+		t.Errorf("expected MaxItems to be overridden to 10, got %d", work.MaxItems)
+	}
+
+	if work.MaxAge != 0 {
+		t.Errorf("expected a negative MaxAge override to disable the limit, got %s", work.MaxAge)
+	}
+
+	other := config.RetentionFor("01K0000000000000000000001", "other")
+	if other != global {
+		t.Errorf("expected collections without an override to use the global policy, got %+v", other)
+	}
+}
+
+func Test_TrashRetentionPeriod(t *testing.T) {
+	tests := []struct {
+		retention time.Duration
+		want      time.Duration
+	}{
+		{retention: 0, want: bore.DefaultTrashRetention},
+		{retention: 48 * time.Hour, want: 48 * time.Hour},
+		{retention: -1, want: 0},
+	}
+
+	for _, tt := range tests {
+		config := &bore.Config{TrashRetention: tt.retention}
+		if got := config.TrashRetentionPeriod(); got != tt.want {
+			t.Errorf("%v: expected %v, got %v", tt.retention, tt.want, got)
+		}
+	}
+}
+
+func Test_SensitiveTTLPeriod(t *testing.T) {
+	tests := []struct {
+		data string
+		want time.Duration
+	}{
+		{data: ``, want: bore.DefaultSensitiveTTL},
+		{data: `sensitive_ttl = "30s"`, want: 30 * time.Second},
+		{data: `sensitive_ttl = "-1s"`, want: 0},
+	}
+
+	for _, tt := range tests {
+		config := &bore.Config{}
+		if _, err := config.FromBytes([]byte(tt.data)); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if got := config.SensitiveTTLPeriod(); got != tt.want {
+			t.Errorf("%q: expected %v, got %v", tt.data, tt.want, got)
+		}
+	}
+}
+
+func Test_BlobThresholdBytes(t *testing.T) {
+	tests := []struct {
+		data string
+		want int64
+	}{
+		{data: ``, want: bore.DefaultBlobThreshold},
+		{data: `blob_threshold = 4096`, want: 4096},
+		{data: `blob_threshold = -1`, want: 0},
+	}
+
+	for _, tt := range tests {
+		config := &bore.Config{}
+		if _, err := config.FromBytes([]byte(tt.data)); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if got := config.BlobThresholdBytes(); got != tt.want {
+			t.Errorf("%q: expected %v, got %v", tt.data, tt.want, got)
+		}
+	}
+}
+
+func Test_SecretPolicyFor(t *testing.T) {
+	config := &bore.Config{}
+	data := `
+	[secret_detection]
+	policy = "mark-sensitive"
+
+	[secret_detection.collection_policy]
+	Vault = "refuse"
+	01JXCOLLECTION = "off"
+	`
+	if _, err := config.FromBytes([]byte(data)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		collectionID   string
+		collectionName string
+		want           bore.SecretPolicy
+	}{
+		{collectionID: "", collectionName: "", want: bore.SecretPolicyMarkSensitive},
+		{collectionID: "01JOTHER", collectionName: "vault", want: bore.SecretPolicyRefuse},
+		{collectionID: "01JXCOLLECTION", collectionName: "notes", want: bore.SecretPolicyOff},
+		{collectionID: "01JOTHER", collectionName: "work", want: bore.SecretPolicyMarkSensitive},
+	}
+
+	for _, tt := range tests {
+		if got := config.SecretDetection.PolicyFor(tt.collectionID, tt.collectionName); got != tt.want {
+			t.Errorf("%s/%s: expected %q, got %q", tt.collectionID, tt.collectionName, tt.want, got)
+		}
+	}
+
+	if got := (bore.SecretDetectionConfig{}).PolicyFor("", ""); got != bore.DefaultSecretPolicy {
+		t.Errorf("expected the default policy, got %q", got)
+	}
+
+	config.SecretDetection.Policy = "ignore"
+	if _, err := config.SecretDetection.Detector(); err == nil {
+		t.Error("expected an invalid policy to be rejected")
+	}
+}
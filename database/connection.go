@@ -149,3 +149,17 @@ func runPragmas(ctx context.Context, tx bun.IDB) error {
 
 	return nil
 }
+
+// Compact rebuilds the database file and truncates the write-ahead log, so rows that were deleted or overwritten no longer linger in free pages.
+func Compact(ctx context.Context, db bun.IDB) error {
+	if _, err := db.NewRaw("PRAGMA wal_checkpoint(TRUNCATE);").Exec(ctx); err != nil {
+		return err
+	}
+
+	if _, err := db.NewRaw("VACUUM;").Exec(ctx); err != nil {
+		return err
+	}
+
+	_, err := db.NewRaw("PRAGMA wal_checkpoint(TRUNCATE);").Exec(ctx)
+	return err
+}
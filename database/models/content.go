@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// Content is a unique content, stored once no matter how many items, trash entries and events reference it by Hash.
+type Content struct {
+	bun.BaseModel `bun:"table:contents,alias:c"`
+
+	// Hash is the checksum of the content, which is replaced with the keyed hash when the database is encrypted, see encryption.Cipher.Hash.
+	Hash    string `bun:"hash,pk"`
+	Content []byte `bun:"content,notnull"`
+
+	// BlobHash is set when the content is kept in the blob store instead of the row, Content is empty then.
+	BlobHash string `bun:"blob_hash,nullzero"`
+	// Size is the size of the content in bytes, wherever it is stored and whether or not it is encrypted.
+	Size int64 `bun:"size,notnull"`
+
+	CreatedAt time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+const (
+	KeySourcePassphrase = "passphrase"
+	KeySourceKeyFile    = "key_file"
+)
+
+// Encryption describes the key the database is encrypted with, there is no row while encryption is disabled.
+type Encryption struct {
+	bun.BaseModel `bun:"table:encryption,alias:enc"`
+
+	ID        int64  `bun:"id,pk"`
+	KeySource string `bun:"key_source,notnull"`
+	// Salt is the Argon2id salt for passphrases, it is nil for key files.
+	Salt []byte `bun:"salt"`
+	// Verifier is a known value sealed with the key, so a wrong key is rejected before any data is decrypted.
+	Verifier []byte `bun:"verifier,notnull"`
+
+	CreatedAt time.Time    `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+	RotatedAt bun.NullTime `bun:"rotated_at"`
+}
@@ -15,17 +15,72 @@ type Item struct {
 	bun.BaseModel `bun:"table:items,alias:i"`
 
 	ID                    string    `bun:"id,pk"                                                 validate:"required,ulid"`
-	Content               []byte    `bun:"content,notnull"                                       validate:"required"`
 	Hash                  string    `bun:"hash,notnull"                                          validate:"required,sha256"`
 	Mimetype              string    `bun:"mimetype,notnull"                                      validate:"required,mimetype"`
 	LastAppliedSequenceID int64     `bun:"last_applied_sequence_id,notnull"                      validate:"gte=0"`
 	CreatedAt             time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
 	UpdatedAt             time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp"`
 
+	// PinnedAt is set while the item is pinned, pinned items are listed first and protected from deletion.
+	PinnedAt bun.NullTime `bun:"pinned_at"`
+
+	// ExpiresAt is the time after which the item is hidden and deleted, if set.
+	ExpiresAt bun.NullTime `bun:"expires_at"`
+	// MaxPastes is the number of pastes after which the item is deleted, zero for no limit.
+	MaxPastes  int `bun:"max_pastes,nullzero"`
+	PasteCount int `bun:"paste_count,notnull"`
+
+	// Sensitive items are masked in listings and left out of the search index.
+	Sensitive bool `bun:"sensitive,notnull"`
+
+	// Content, BlobHash and ContentSize are loaded from the content the item references by Hash, see Content.
+	Content     []byte `bun:"content,scanonly"`
+	BlobHash    string `bun:"blob_hash,scanonly"`
+	ContentSize int64  `bun:"content_size,scanonly"`
+
 	CollectionID sql.NullString `bun:"collection_id"`
 	Collection   *Collection    `bun:"rel:belongs-to,join:collection_id=id"`
 }
 
+type Items []*Item
+
+// ItemSummary is a lightweight view of an item that leaves out its content.
+type ItemSummary struct {
+	ID                    string         `bun:"id"`
+	CollectionID          sql.NullString `bun:"collection_id"`
+	Size                  int64          `bun:"size"`
+	LastAppliedSequenceID int64          `bun:"last_applied_sequence_id"`
+	CopiedAt              time.Time      `bun:"copied_at"` // When the item was last copied, pins and pastes do not count.
+}
+
+// IsPinned reports whether the item is currently pinned.
+func (item *Item) IsPinned() bool {
+	return !item.PinnedAt.IsZero()
+}
+
+// InBlobStore reports whether the content is kept in the blob store, so it has to be read with clipboardNamespace.OpenContent.
+func (item *Item) InBlobStore() bool {
+	return item.BlobHash != ""
+}
+
+// Size returns the size of the content in bytes, wherever it is stored.
+func (item *Item) Size() int64 {
+	if item.InBlobStore() {
+		return item.ContentSize
+	}
+
+	return int64(len(item.Content))
+}
+
+// IsExpired reports whether the item has outlived its expiry time or paste limit.
+func (item *Item) IsExpired(now time.Time) bool {
+	if !item.ExpiresAt.IsZero() && !now.Before(item.ExpiresAt.Time) {
+		return true
+	}
+
+	return item.MaxPastes > 0 && item.PasteCount >= item.MaxPastes
+}
+
 // BeforeAppendModel implements schema.BeforeAppendModelHook.
 func (item *Item) BeforeAppendModel(ctx context.Context, query schema.Query) error {
 	if err := item.Validate(); err != nil {
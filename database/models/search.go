@@ -0,0 +1,14 @@
+package models
+
+// ItemSearchResult is an item matched by a search query.
+type ItemSearchResult struct {
+	Item `bun:",extend"`
+
+	// Snippet is an excerpt of the content around the match with the matched terms highlighted.
+	Snippet string `bun:"snippet"`
+
+	// Rank is the relevance of the match, lower is better.
+	Rank float64 `bun:"rank"`
+}
+
+type ItemSearchResults []*ItemSearchResult
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// TrashEntry is a deleted item or collection that can still be restored.
+// Items deleted together with their collection share the sequence ID of the collection's delete event.
+type TrashEntry struct {
+	bun.BaseModel `bun:"table:trash,alias:t"`
+
+	ID            int64  `bun:"id,pk,autoincrement"`
+	AggregateType string `bun:"aggregate_type,notnull"`
+	AggregateID   string `bun:"aggregate_id,notnull"`
+	SequenceID    int64  `bun:"sequence_id,notnull"`
+
+	// Name is only set for collections.
+	Name sql.NullString `bun:"name"`
+
+	// The remaining fields are only set for items.
+	CollectionID sql.NullString `bun:"collection_id"`
+	Hash         sql.NullString `bun:"hash"`
+	Mimetype     sql.NullString `bun:"mimetype"`
+	PinnedAt     bun.NullTime   `bun:"pinned_at"`
+	ExpiresAt    bun.NullTime   `bun:"expires_at"`
+	MaxPastes    int            `bun:"max_pastes,nullzero"`
+	PasteCount   int            `bun:"paste_count,notnull"`
+	Sensitive    bool           `bun:"sensitive,notnull"`
+
+	// Content, BlobHash and ContentSize are loaded from the content the item references by Hash, see Item.
+	Content     []byte `bun:"content,scanonly"`
+	BlobHash    string `bun:"blob_hash,scanonly"`
+	ContentSize int64  `bun:"content_size,scanonly"`
+
+	// LastAppliedSequenceID and UpdatedAt are restored as-is, so items return to their place in the history.
+	LastAppliedSequenceID int64        `bun:"last_applied_sequence_id,nullzero"`
+	UpdatedAt             bun.NullTime `bun:"updated_at"`
+
+	CreatedAt time.Time `bun:"created_at,notnull"`
+	DeletedAt time.Time `bun:"deleted_at,notnull"`
+
+	// ItemsCount is the number of items deleted with a collection, it is only loaded when listing the trash.
+	ItemsCount int `bun:"items_count,scanonly"`
+}
+
+type TrashEntries []*TrashEntry
+
+// Size returns the size of a trashed item's content in bytes, see Item.Size.
+func (entry *TrashEntry) Size() int64 {
+	if entry.BlobHash != "" {
+		return entry.ContentSize
+	}
+
+	return int64(len(entry.Content))
+}
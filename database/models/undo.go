@@ -0,0 +1,33 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// UndoEntry is a user operation that can be undone, along with the events that revert it and the events that apply it again.
+type UndoEntry struct {
+	bun.BaseModel `bun:"table:undo_entries,alias:u"`
+
+	ID          int64       `bun:"id,pk,autoincrement"`
+	Description string      `bun:"description,notnull"`
+	UndoEvents  []UndoEvent `bun:"undo_events,type:json,notnull"`
+	RedoEvents  []UndoEvent `bun:"redo_events,type:json,notnull"`
+
+	// UndoneAt is set while the entry has been undone and can be redone.
+	UndoneAt  bun.NullTime `bun:"undone_at"`
+	CreatedAt time.Time    `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+}
+
+// UndoEvent is an event stored on the undo stack, it is only assigned an ID and sequence once it is applied.
+type UndoEvent struct {
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	Type          string          `json:"type"`
+	Payload       json.RawMessage `json:"payload"`
+
+	// ContentHash is the hash of the content the payload references, kept outside the payload so it is readable when the payload is encrypted.
+	ContentHash string `json:"content_hash,omitempty"`
+}
@@ -117,16 +117,26 @@ func (c *collectionRepository) FindOne(
 	query := c.db.NewSelect().Model(collection).
 		Where("id = ?", opts.Identifier).
 		WhereOr("LOWER(name) = LOWER(?)", opts.Name).
+		OrderExpr("id = ? DESC", opts.Identifier).
 		Limit(1)
 	err := query.Scan(ctx)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, nil
-		}
+	if err == nil {
+		return collection, nil
+	} else if !errors.Is(err, sql.ErrNoRows) {
 		return nil, errs.Wrap(err, "failed to find collection")
 	}
 
-	return collection, nil
+	// Exact IDs and names take precedence, the identifier is only treated as an ID prefix when nothing else matches.
+	identifier, err := c.ResolveID(ctx, opts.Identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	if identifier == "" || identifier == opts.Identifier {
+		return nil, nil
+	}
+
+	return c.FindById(ctx, identifier)
 }
 
 // FindByName implements CollectionRepository.
@@ -188,3 +198,16 @@ func (c *collectionRepository) FindById(
 }
 
 var _ CollectionRepository = (*collectionRepository)(nil)
+
+// ResolveID implements CollectionRepository.
+func (c *collectionRepository) ResolveID(ctx context.Context, prefix string) (string, error) {
+	return resolveIDPrefix(ctx, c.db, "collections", prefix)
+}
+
+// ShortestPrefixes implements CollectionRepository.
+func (c *collectionRepository) ShortestPrefixes(
+	ctx context.Context,
+	ids []string,
+) (map[string]string, error) {
+	return shortestIDPrefixes(ctx, c.db, "collections", ids)
+}
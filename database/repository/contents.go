@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/uptrace/bun"
+	"go.trulyao.dev/bore/v2/database/models"
+	"go.trulyao.dev/bore/v2/pkg/encryption"
+	"go.trulyao.dev/bore/v2/pkg/errs"
+)
+
+// unreferencedContentsQuery matches every content that no item, trash entry, event or undo entry references any more.
+// Undo entries are cast to text since SQLite treats BLOB arguments to the JSON functions as JSONB, the hashes live outside the (possibly encrypted) payloads.
+const unreferencedContentsQuery = `
+NOT EXISTS (SELECT 1 FROM items WHERE items.hash = c.hash)
+AND NOT EXISTS (SELECT 1 FROM trash WHERE trash.hash = c.hash)
+AND NOT EXISTS (SELECT 1 FROM events WHERE events.content_hash = c.hash)
+AND NOT EXISTS (
+	SELECT 1 FROM undo_entries, json_each(CAST(undo_events AS TEXT)) AS e
+	WHERE json_extract(e.value, '$.content_hash') = c.hash
+)
+AND NOT EXISTS (
+	SELECT 1 FROM undo_entries, json_each(CAST(redo_events AS TEXT)) AS e
+	WHERE json_extract(e.value, '$.content_hash') = c.hash
+)`
+
+// contentRepository seals contents and keys their hashes the same way the item repository does, callers only ever see plaintext checksums.
+type contentRepository struct {
+	db     *bun.DB
+	cipher *encryption.Cipher
+}
+
+// Put implements ContentRepository.
+func (c *contentRepository) Put(ctx context.Context, tx bun.Tx, content *models.Content) error {
+	return putContent(ctx, tx, c.cipher, content)
+}
+
+// Find implements ContentRepository.
+func (c *contentRepository) Find(ctx context.Context, db bun.IDB, checksum string) (*models.Content, error) {
+	content := new(models.Content)
+	err := db.NewSelect().Model(content).Where("hash = ?", c.cipher.Hash(checksum)).Limit(1).Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if c.cipher.Enabled() && content.BlobHash == "" {
+		if content.Content, err = c.cipher.Open(content.Content); err != nil {
+			return nil, errs.Wrap(err, "failed to decrypt content "+checksum)
+		}
+	}
+
+	content.Hash = checksum
+	return content, nil
+}
+
+// DeleteUnreferenced implements ContentRepository.
+func (c *contentRepository) DeleteUnreferenced(ctx context.Context, tx bun.Tx) (int64, error) {
+	result, err := tx.NewDelete().Model((*models.Content)(nil)).Where(unreferencedContentsQuery).Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// FindBlobs implements ContentRepository.
+func (c *contentRepository) FindBlobs(ctx context.Context) (map[string]struct{}, error) {
+	var hashes []string
+	err := c.db.NewSelect().
+		Model((*models.Content)(nil)).
+		Column("blob_hash").
+		Where("blob_hash IS NOT NULL").
+		Scan(ctx, &hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	referenced := make(map[string]struct{}, len(hashes))
+	for _, hash := range hashes {
+		referenced[hash] = struct{}{}
+	}
+
+	return referenced, nil
+}
+
+// putContent stores the content sealed with the cipher under its keyed hash, unless it is already stored.
+// Contents in the blob store are only referenced, they are never encrypted.
+func putContent(ctx context.Context, tx bun.Tx, cipher *encryption.Cipher, content *models.Content) error {
+	row := *content
+	row.Hash = cipher.Hash(content.Hash)
+
+	if row.BlobHash == "" {
+		sealed, err := cipher.Seal(content.Content)
+		if err != nil {
+			return err
+		}
+
+		row.Content, row.Size = sealed, int64(len(content.Content))
+	}
+
+	// Empty content is stored as an empty BLOB, a nil slice would be inserted as NULL.
+	if row.Content == nil {
+		row.Content = []byte{}
+	}
+
+	_, err := tx.NewInsert().Model(&row).On("CONFLICT (hash) DO NOTHING").Exec(ctx)
+	return err
+}
+
+var _ ContentRepository = (*contentRepository)(nil)
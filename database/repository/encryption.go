@@ -0,0 +1,296 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/uptrace/bun"
+	"go.trulyao.dev/bore/v2/database/models"
+	"go.trulyao.dev/bore/v2/pkg/blobstore"
+	"go.trulyao.dev/bore/v2/pkg/encryption"
+	"go.trulyao.dev/bore/v2/pkg/errs"
+	"go.trulyao.dev/bore/v2/pkg/events/action"
+	"go.trulyao.dev/bore/v2/pkg/lib"
+)
+
+type encryptionRepository struct {
+	db *bun.DB
+}
+
+// Find implements EncryptionRepository.
+func (e *encryptionRepository) Find(ctx context.Context) (*models.Encryption, error) {
+	key := new(models.Encryption)
+	if err := e.db.NewSelect().Model(key).Limit(1).Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// Rekey implements EncryptionRepository.
+func (e *encryptionRepository) Rekey(
+	ctx context.Context,
+	tx bun.Tx,
+	from, to *encryption.Cipher,
+	key *models.Encryption,
+	blobs *blobstore.Store,
+) error {
+	if !to.Enabled() || key == nil {
+		return errs.New("a new encryption key is required")
+	}
+
+	if err := rekeyContents(ctx, tx, from, to, blobs); err != nil {
+		return errs.Wrap(err, "failed to re-encrypt contents")
+	}
+
+	if err := rekeyEvents(ctx, tx, from, to); err != nil {
+		return errs.Wrap(err, "failed to re-encrypt events")
+	}
+
+	if err := rekeyUndoEntries(ctx, tx, from, to); err != nil {
+		return errs.Wrap(err, "failed to re-encrypt undo history")
+	}
+
+	// Deleted rows leave their terms in the index segments until they are merged, so drop those too.
+	if _, err := tx.NewRaw("DELETE FROM items_fts").Exec(ctx); err != nil {
+		return err
+	}
+
+	if _, err := tx.NewRaw("INSERT INTO items_fts (items_fts) VALUES ('rebuild')").Exec(ctx); err != nil {
+		return err
+	}
+
+	key.ID = 1
+	if from.Enabled() {
+		key.RotatedAt = bun.NullTime{Time: time.Now().UTC()}
+	}
+
+	_, err := tx.NewInsert().
+		Model(key).
+		On("CONFLICT (id) DO UPDATE").
+		Set("key_source = EXCLUDED.key_source").
+		Set("salt = EXCLUDED.salt").
+		Set("verifier = EXCLUDED.verifier").
+		Set("rotated_at = EXCLUDED.rotated_at").
+		Exec(ctx)
+	return err
+}
+
+// rekeyContents re-encrypts every content and moves it to its new keyed hash, along with the items and trash entries that reference it.
+// Contents kept in the blob store are moved into their rows.
+func rekeyContents(
+	ctx context.Context,
+	tx bun.Tx,
+	from, to *encryption.Cipher,
+	blobs *blobstore.Store,
+) error {
+	var rows []*models.Content
+	if err := tx.NewSelect().Model(&rows).Scan(ctx); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		var (
+			content []byte
+			err     error
+		)
+		if row.BlobHash != "" {
+			content, err = blobs.ReadAll(row.BlobHash)
+			if err != nil {
+				return errs.Wrap(err, "failed to read blob "+row.BlobHash)
+			}
+		} else if content, err = from.Open(row.Content); err != nil {
+			return err
+		}
+
+		sealed, err := to.Seal(content)
+		if err != nil {
+			return err
+		}
+
+		hash := to.Hash(lib.ComputeChecksum(content))
+		_, err = tx.NewUpdate().
+			Table("contents").
+			Set("hash = ?", hash).
+			Set("content = ?", sealed).
+			Set("blob_hash = NULL").
+			Where("hash = ?", row.Hash).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, table := range []string{"items", "trash"} {
+			_, err = tx.NewUpdate().Table(table).Set("hash = ?", hash).Where("hash = ?", row.Hash).Exec(ctx)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// rekeyEvents re-encrypts every payload and recomputes the keyed hash of the content it references.
+func rekeyEvents(ctx context.Context, tx bun.Tx, from, to *encryption.Cipher) error {
+	var rows []struct {
+		ID      string          `bun:"event_id"`
+		Type    string          `bun:"type"`
+		Payload json.RawMessage `bun:"payload"`
+	}
+	if err := tx.NewSelect().Table("events").Column("event_id", "type", "payload").Scan(ctx, &rows); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		payload, err := from.OpenJSON(row.Payload)
+		if err != nil {
+			return err
+		}
+
+		payload, checksum, err := referenceContent(ctx, tx, to, row.Type, payload)
+		if err != nil {
+			return err
+		}
+
+		if payload, err = to.SealJSON(payload); err != nil {
+			return err
+		}
+
+		_, err = tx.NewUpdate().
+			Table("events").
+			Set("payload = ?", string(payload)).
+			Set("content_hash = ?", keyedHash(to, checksum)).
+			Where("event_id = ?", row.ID).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func rekeyUndoEntries(ctx context.Context, tx bun.Tx, from, to *encryption.Cipher) error {
+	var entries []*models.UndoEntry
+	if err := tx.NewSelect().Model(&entries).Scan(ctx); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if err := rekeyUndoEvents(ctx, tx, entry.UndoEvents, from, to); err != nil {
+			return err
+		}
+
+		if err := rekeyUndoEvents(ctx, tx, entry.RedoEvents, from, to); err != nil {
+			return err
+		}
+
+		_, err := tx.NewUpdate().
+			Model(entry).
+			Column("undo_events", "redo_events").
+			WherePK().
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func rekeyUndoEvents(
+	ctx context.Context,
+	tx bun.Tx,
+	events []models.UndoEvent,
+	from, to *encryption.Cipher,
+) error {
+	for i := range events {
+		payload, err := from.OpenJSON(events[i].Payload)
+		if err != nil {
+			return err
+		}
+
+		payload, checksum, err := referenceContent(ctx, tx, to, events[i].Type, payload)
+		if err != nil {
+			return err
+		}
+
+		if events[i].Payload, err = to.SealJSON(payload); err != nil {
+			return err
+		}
+
+		events[i].ContentHash = keyedHash(to, checksum).String
+	}
+
+	return nil
+}
+
+// referenceContent returns the checksum of the content a payload references, if any.
+// Payloads recorded before contents were deduplicated carry their content inline, it is moved into the contents table and replaced with its checksum.
+func referenceContent(
+	ctx context.Context,
+	tx bun.Tx,
+	cipher *encryption.Cipher,
+	eventType string,
+	payload json.RawMessage,
+) (json.RawMessage, string, error) {
+	if eventType != action.ActionCreateItem.String() && eventType != action.ActionUpdateItemContent.String() {
+		return payload, "", nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return nil, "", err
+	}
+
+	var checksum string
+	if raw, ok := fields["content_hash"]; ok {
+		if err := json.Unmarshal(raw, &checksum); err != nil {
+			return nil, "", err
+		}
+	}
+
+	raw, ok := fields["content"]
+	if !ok {
+		return payload, checksum, nil
+	}
+
+	// Redacted payloads have a null content, and reference nothing.
+	var content []byte
+	if err := json.Unmarshal(raw, &content); err != nil {
+		return nil, "", err
+	}
+
+	delete(fields, "content")
+	if content != nil {
+		checksum = lib.ComputeChecksum(content)
+		stored := &models.Content{Hash: checksum, Content: content, Size: int64(len(content))}
+		if err := putContent(ctx, tx, cipher, stored); err != nil {
+			return nil, "", err
+		}
+
+		hash, err := json.Marshal(checksum)
+		if err != nil {
+			return nil, "", err
+		}
+
+		fields["content_hash"] = hash
+	}
+
+	payload, err := json.Marshal(fields)
+	return payload, checksum, err
+}
+
+// keyedHash returns the keyed hash of the checksum for a content_hash column, NULL if there is no checksum.
+func keyedHash(cipher *encryption.Cipher, checksum string) sql.NullString {
+	return sql.NullString{String: cipher.Hash(checksum), Valid: checksum != ""}
+}
+
+var _ EncryptionRepository = (*encryptionRepository)(nil)
@@ -5,14 +5,19 @@ import (
 	"database/sql"
 	"errors"
 	"strings"
+	"time"
 
 	"github.com/uptrace/bun"
 	"go.trulyao.dev/bore/v2/database/models"
+	"go.trulyao.dev/bore/v2/pkg/encryption"
 	"go.trulyao.dev/bore/v2/pkg/errs"
+	"go.trulyao.dev/bore/v2/pkg/lib"
+	"go.trulyao.dev/bore/v2/pkg/mimetype"
 )
 
 type itemRepository struct {
-	db *bun.DB
+	db     *bun.DB
+	cipher *encryption.Cipher
 }
 
 // Bump implements ItemRepository.
@@ -40,9 +45,57 @@ func (i *itemRepository) Bump(
 	return err
 }
 
+// SetLimits implements ItemRepository.
+func (i *itemRepository) SetLimits(
+	ctx context.Context,
+	tx bun.Tx,
+	identifier string,
+	expiresAt time.Time,
+	maxPastes int,
+) error {
+	identifier = strings.TrimSpace(identifier)
+	if identifier == "" {
+		return ErrEmptyIdentifier
+	}
+
+	query := tx.NewUpdate().
+		Model((*models.Item)(nil)).
+		Where("id = ?", identifier)
+
+	if !expiresAt.IsZero() {
+		query.Set("expires_at = ?", formatTimestamp(expiresAt))
+	}
+
+	if maxPastes > 0 {
+		query.Set("max_pastes = ?", maxPastes).Set("paste_count = 0")
+	}
+
+	_, err := query.Exec(ctx)
+	return err
+}
+
+// MarkSensitive implements ItemRepository.
+func (i *itemRepository) MarkSensitive(ctx context.Context, tx bun.Tx, identifier string) error {
+	identifier = strings.TrimSpace(identifier)
+	if identifier == "" {
+		return ErrEmptyIdentifier
+	}
+
+	_, err := tx.NewUpdate().
+		Model((*models.Item)(nil)).
+		Set("sensitive = ?", true).
+		Where("id = ?", identifier).
+		Exec(ctx)
+	return err
+}
+
 // Create implements ItemRepository.
 func (i *itemRepository) Create(ctx context.Context, tx bun.Tx, item *models.Item) error {
-	_, err := tx.NewInsert().Model(item).Ignore().Exec(ctx)
+	// Key the hash of a copy, the caller still needs the checksum.
+	row := *item
+	row.Hash = i.cipher.Hash(item.Hash)
+
+	_, err := tx.NewInsert().Model(&row).Ignore().Exec(ctx)
 	return err
 }
 
@@ -61,19 +114,57 @@ func (i *itemRepository) DeleteById(ctx context.Context, tx bun.Tx, identifier s
 	return nil
 }
 
+// DeleteUnpinnedById implements ItemRepository.
+func (i *itemRepository) DeleteUnpinnedById(ctx context.Context, tx bun.Tx, identifier string) error {
+	identifier = strings.TrimSpace(identifier)
+	if identifier == "" {
+		return ErrEmptyIdentifier
+	}
+
+	res, err := tx.NewDelete().
+		Model((*models.Item)(nil)).
+		Where("id = ?", identifier).
+		Where("pinned_at IS NULL").
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected > 0 {
+		return nil
+	}
+
+	// Nothing was deleted, either because the item is pinned or because it is already gone.
+	pinned, err := tx.NewSelect().Model((*models.Item)(nil)).Where("id = ?", identifier).Exists(ctx)
+	if err != nil {
+		return err
+	}
+
+	if pinned {
+		return errs.ErrItemPinned
+	}
+
+	return nil
+}
+
 // FindByHash implements ItemRepository.
 func (i *itemRepository) FindByHash(
 	ctx context.Context,
 	hash string,
 	collectionId string,
 ) (*models.Item, error) {
-	hash = strings.TrimSpace(hash)
+	hash = i.cipher.Hash(strings.TrimSpace(hash))
 
 	item := new(models.Item)
-	query := i.db.NewSelect().Model(item).
-		Where("hash = ?", hash)
+	query := withContent(i.db.NewSelect().Model(item)).
+		Where("i.hash = ?", hash)
 	if collectionId != "" {
-		query.Where("collection_id = ?", collectionId)
+		query.Where("i.collection_id = ?", collectionId)
 	}
 
 	if err := query.Limit(1).Scan(ctx, item); err != nil {
@@ -83,7 +174,7 @@ func (i *itemRepository) FindByHash(
 		return nil, err
 	}
 
-	return item, nil
+	return item, i.open(item)
 }
 
 // FindById implements ItemRepository.
@@ -92,14 +183,18 @@ func (i *itemRepository) FindById(
 	identifier string,
 	collectionId string,
 ) (*models.Item, error) {
-	identifier = strings.TrimSpace(identifier)
+	identifier, err := i.ResolveID(ctx, identifier)
+	if err != nil || identifier == "" {
+		return nil, err
+	}
 
 	item := new(models.Item)
-	query := i.db.NewSelect().Model(item).
-		Where("id = ?", identifier)
+	query := withContent(i.db.NewSelect().Model(item)).
+		Where("i.id = ?", identifier)
+	whereNotExpired(query, time.Now())
 
 	if collectionId != "" {
-		query.Where("collection_id = ?", collectionId)
+		query.Where("i.collection_id = ?", collectionId)
 	}
 
 	if err := query.Limit(1).Scan(ctx, item); err != nil {
@@ -109,26 +204,37 @@ func (i *itemRepository) FindById(
 		return nil, err
 	}
 
-	return item, nil
+	return item, i.open(item)
 }
 
 // GetLastItem implements ItemRepository.
 func (i *itemRepository) FindLatest(
 	ctx context.Context,
 	collectionID string,
+) (*models.Item, error) {
+	return i.FindNthLatest(ctx, collectionID, 0)
+}
+
+// FindNthLatest implements ItemRepository.
+func (i *itemRepository) FindNthLatest(
+	ctx context.Context,
+	collectionID string,
+	offset int,
 ) (*models.Item, error) {
 	collectionID = strings.TrimSpace(collectionID)
 
 	item := new(models.Item)
-	query := i.db.NewSelect().Model(item).
-		Order("last_applied_sequence_id DESC").
-		Order("updated_at DESC").
+	query := withContent(i.db.NewSelect().Model(item)).
+		Order("i.last_applied_sequence_id DESC").
+		Order("i.updated_at DESC").
+		Offset(offset).
 		Limit(1)
+	whereNotExpired(query, time.Now())
 
 	if collectionID != "" {
-		query.Where("collection_id = ?", collectionID)
+		query.Where("i.collection_id = ?", collectionID)
 	} else {
-		query.Where("collection_id IS NULL")
+		query.Where("i.collection_id IS NULL")
 	}
 
 	if err := query.Scan(ctx, item); err != nil {
@@ -138,7 +244,462 @@ func (i *itemRepository) FindLatest(
 		return nil, err
 	}
 
-	return item, nil
+	return item, i.open(item)
+}
+
+// FindAll implements ItemRepository.
+func (i *itemRepository) FindAll(
+	ctx context.Context,
+	opts FindItemsOptions,
+) (models.Items, error) {
+	var items models.Items
+	query := withContent(i.db.NewSelect().Model(&items)).
+		Relation("Collection")
+
+	if opts.PinnedFirst {
+		query.OrderExpr("i.pinned_at IS NULL ASC")
+	}
+
+	query.
+		Order("i.last_applied_sequence_id DESC").
+		Order("i.updated_at DESC")
+
+	whereNotExpired(query, time.Now())
+
+	if collectionID := strings.TrimSpace(opts.CollectionID); collectionID != "" {
+		query.Where("i.collection_id = ?", collectionID)
+	}
+
+	if filter := strings.TrimSpace(opts.Mimetype); filter != "" {
+		whereMimetype(query, filter)
+	}
+
+	if !opts.CreatedAfter.IsZero() {
+		query.Where("i.created_at >= ?", formatTimestamp(opts.CreatedAfter))
+	}
+
+	if !opts.CreatedBefore.IsZero() {
+		query.Where("i.created_at < ?", formatTimestamp(opts.CreatedBefore))
+	}
+
+	if !opts.UpdatedAfter.IsZero() {
+		query.Where("i.updated_at >= ?", formatTimestamp(opts.UpdatedAfter))
+	}
+
+	if !opts.UpdatedBefore.IsZero() {
+		query.Where("i.updated_at < ?", formatTimestamp(opts.UpdatedBefore))
+	}
+
+	if cursor := opts.BeforeSequenceID; cursor > 0 {
+		switch {
+		case opts.PinnedFirst && opts.BeforePinned:
+			// The rest of the pinned run, followed by every unpinned item.
+			query.WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+				return q.Where("i.pinned_at IS NOT NULL AND i.last_applied_sequence_id < ?", cursor).
+					WhereOr("i.pinned_at IS NULL")
+			})
+		case opts.PinnedFirst:
+			query.Where("i.pinned_at IS NULL AND i.last_applied_sequence_id < ?", cursor)
+		default:
+			query.Where("i.last_applied_sequence_id < ?", cursor)
+		}
+	}
+
+	if opts.Limit > 0 {
+		query.Limit(opts.Limit)
+	}
+
+	if err := query.Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		if err := i.open(item); err != nil {
+			return nil, err
+		}
+	}
+
+	return items, nil
+}
+
+// Pin implements ItemRepository.
+func (i *itemRepository) Pin(
+	ctx context.Context,
+	tx bun.Tx,
+	identifier string,
+	pinnedAt time.Time,
+) error {
+	return i.setPinnedAt(ctx, tx, identifier, formatTimestamp(pinnedAt))
+}
+
+// Unpin implements ItemRepository.
+func (i *itemRepository) Unpin(ctx context.Context, tx bun.Tx, identifier string) error {
+	return i.setPinnedAt(ctx, tx, identifier, nil)
+}
+
+func (i *itemRepository) setPinnedAt(
+	ctx context.Context,
+	tx bun.Tx,
+	identifier string,
+	value any,
+) error {
+	identifier = strings.TrimSpace(identifier)
+	if identifier == "" {
+		return ErrEmptyIdentifier
+	}
+
+	res, err := tx.NewUpdate().
+		Model((*models.Item)(nil)).
+		Set("pinned_at = ?", value).
+		Where("id = ?", identifier).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errs.New("no item found with the given identifier")
+	}
+
+	return nil
+}
+
+// CountPinned implements ItemRepository.
+func (i *itemRepository) CountPinned(ctx context.Context, collectionID string) (int, error) {
+	query := i.db.NewSelect().
+		Model((*models.Item)(nil)).
+		Where("pinned_at IS NOT NULL")
+
+	if collectionID = strings.TrimSpace(collectionID); collectionID != "" {
+		query.Where("collection_id = ?", collectionID)
+	}
+
+	return query.Count(ctx)
+}
+
+// FindUnpinnedSummaries implements ItemRepository.
+func (i *itemRepository) FindUnpinnedSummaries(
+	ctx context.Context,
+	collectionID string,
+) ([]models.ItemSummary, error) {
+	// The event that last moved the item to the top of the history is the last time it was copied, updated_at also changes on pins.
+	query := i.db.NewSelect().
+		Model((*models.Item)(nil)).
+		Column("i.id", "i.collection_id", "i.last_applied_sequence_id").
+		ColumnExpr("c.size AS size").
+		ColumnExpr("COALESCE(e.occurred_at, i.created_at) AS copied_at").
+		Join("JOIN contents AS c ON c.hash = i.hash").
+		Join("LEFT JOIN events AS e ON e.sequence_id = i.last_applied_sequence_id").
+		Where("i.pinned_at IS NULL").
+		Order("i.last_applied_sequence_id DESC")
+
+	if collectionID = strings.TrimSpace(collectionID); collectionID != "" {
+		query.Where("i.collection_id = ?", collectionID)
+	} else {
+		query.Where("i.collection_id IS NULL")
+	}
+
+	var summaries []models.ItemSummary
+	if err := query.Scan(ctx, &summaries); err != nil {
+		return nil, err
+	}
+
+	return summaries, nil
+}
+
+// IncrementPasteCount implements ItemRepository.
+func (i *itemRepository) IncrementPasteCount(
+	ctx context.Context,
+	tx bun.Tx,
+	identifier string,
+) error {
+	identifier = strings.TrimSpace(identifier)
+	if identifier == "" {
+		return ErrEmptyIdentifier
+	}
+
+	_, err := tx.NewUpdate().
+		Model((*models.Item)(nil)).
+		Set("paste_count = paste_count + 1").
+		Where("id = ?", identifier).
+		Exec(ctx)
+	return err
+}
+
+// Move implements ItemRepository.
+func (i *itemRepository) Move(
+	ctx context.Context,
+	tx bun.Tx,
+	identifier string,
+	collectionID string,
+) (string, error) {
+	identifier = strings.TrimSpace(identifier)
+	if identifier == "" {
+		return "", ErrEmptyIdentifier
+	}
+
+	target := sql.NullString{String: collectionID, Valid: collectionID != ""}
+
+	item := new(models.Item)
+	err := tx.NewSelect().Model(item).Where("id = ?", identifier).Limit(1).Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", errs.New("item not found")
+		}
+		return "", err
+	}
+
+	duplicate, err := i.findDuplicate(ctx, tx, item.ID, item.Hash, target)
+	if err != nil {
+		return "", err
+	}
+
+	if duplicate != nil {
+		return duplicate.ID, i.mergeInto(ctx, tx, item, duplicate)
+	}
+
+	_, err = tx.NewUpdate().
+		Model((*models.Item)(nil)).
+		Set("collection_id = ?", target).
+		Where("id = ?", item.ID).
+		Exec(ctx)
+	return "", err
+}
+
+// UpdateContent implements ItemRepository.
+func (i *itemRepository) UpdateContent(
+	ctx context.Context,
+	tx bun.Tx,
+	identifier string,
+	hash string,
+	sequenceID int64,
+	updatedAt time.Time,
+) (string, error) {
+	identifier = strings.TrimSpace(identifier)
+	if identifier == "" {
+		return "", ErrEmptyIdentifier
+	}
+
+	item := new(models.Item)
+	err := tx.NewSelect().Model(item).Where("id = ?", identifier).Limit(1).Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", errs.New("item not found")
+		}
+		return "", err
+	}
+
+	hash = i.cipher.Hash(hash)
+	duplicate, err := i.findDuplicate(ctx, tx, item.ID, hash, item.CollectionID)
+	if err != nil {
+		return "", err
+	}
+
+	if duplicate != nil {
+		if err := i.mergeInto(ctx, tx, item, duplicate); err != nil {
+			return "", err
+		}
+
+		_, err = tx.NewUpdate().
+			Model((*models.Item)(nil)).
+			Set("last_applied_sequence_id = ?", sequenceID).
+			Where("id = ?", duplicate.ID).
+			Exec(ctx)
+		return duplicate.ID, err
+	}
+
+	_, err = tx.NewUpdate().
+		Model((*models.Item)(nil)).
+		Set("hash = ?", hash).
+		Set("last_applied_sequence_id = ?", sequenceID).
+		Set("updated_at = ?", formatTimestamp(updatedAt)).
+		Where("id = ?", item.ID).
+		Exec(ctx)
+	return "", err
+}
+
+// AppendContent implements ItemRepository.
+func (i *itemRepository) AppendContent(
+	ctx context.Context,
+	tx bun.Tx,
+	identifier string,
+	suffix []byte,
+	sequenceID int64,
+	updatedAt time.Time,
+) ([]byte, string, error) {
+	item := new(models.Item)
+	err := withContent(tx.NewSelect().Model(item)).
+		Where("i.id = ?", strings.TrimSpace(identifier)).
+		Limit(1).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, "", errs.New("item not found")
+		}
+		return nil, "", err
+	}
+
+	if err := i.open(item); err != nil {
+		return nil, "", err
+	}
+
+	// Only the combined content is stored, the appended part alone is kept in the event.
+	content := append(item.Content, suffix...)
+	stored := &models.Content{Hash: lib.ComputeChecksum(content), Content: content, Size: int64(len(content))}
+	if err := putContent(ctx, tx, i.cipher, stored); err != nil {
+		return nil, "", err
+	}
+
+	mergedInto, err := i.UpdateContent(ctx, tx, item.ID, stored.Hash, sequenceID, updatedAt)
+	return content, mergedInto, err
+}
+
+// open decrypts the content of an item read from the database and restores its checksum, so callers only ever see the plaintext.
+func (i *itemRepository) open(item *models.Item) error {
+	if !i.cipher.Enabled() || item == nil || item.InBlobStore() {
+		return nil
+	}
+
+	content, err := i.cipher.Open(item.Content)
+	if err != nil {
+		return errs.Wrap(err, "failed to decrypt item "+item.ID)
+	}
+
+	item.Content = content
+	item.Hash = lib.ComputeChecksum(content)
+	return nil
+}
+
+// findDuplicate returns another item with the same hash in the collection, or nil if there is none.
+func (i *itemRepository) findDuplicate(
+	ctx context.Context,
+	tx bun.Tx,
+	itemID string,
+	hash string,
+	collectionID sql.NullString,
+) (*models.Item, error) {
+	duplicate := new(models.Item)
+	err := tx.NewSelect().Model(duplicate).
+		Where("hash = ?", hash).
+		Where("collection_id IS ?", collectionID).
+		Where("id != ?", itemID).
+		Limit(1).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return duplicate, nil
+}
+
+// mergeInto folds the item into a duplicate with the same content, which the unique index would otherwise reject.
+func (i *itemRepository) mergeInto(
+	ctx context.Context,
+	tx bun.Tx,
+	item *models.Item,
+	duplicate *models.Item,
+) error {
+	// Keep the pin of the merged item, since the user would otherwise lose it silently.
+	if item.IsPinned() && !duplicate.IsPinned() {
+		if err := i.Pin(ctx, tx, duplicate.ID, item.PinnedAt.Time); err != nil {
+			return err
+		}
+	}
+
+	return i.DeleteById(ctx, tx, item.ID)
+}
+
+// FindExpired implements ItemRepository.
+func (i *itemRepository) FindExpired(
+	ctx context.Context,
+	now time.Time,
+) ([]models.ItemSummary, error) {
+	var summaries []models.ItemSummary
+	err := i.db.NewSelect().
+		Model((*models.Item)(nil)).
+		Column("id", "collection_id", "last_applied_sequence_id").
+		ColumnExpr("c.size AS size").
+		Join("JOIN contents AS c ON c.hash = i.hash").
+		Where("i.pinned_at IS NULL").
+		WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Where("i.expires_at <= ?", formatTimestamp(now)).
+				WhereOr("i.max_pastes IS NOT NULL AND i.paste_count >= i.max_pastes")
+		}).
+		Order("i.last_applied_sequence_id DESC").
+		Scan(ctx, &summaries)
+	if err != nil {
+		return nil, err
+	}
+
+	return summaries, nil
+}
+
+// withContent loads the content each item references along with the item, see models.Item.Content.
+func withContent(query *bun.SelectQuery) *bun.SelectQuery {
+	return query.
+		ColumnExpr("i.*").
+		ColumnExpr("c.content, c.blob_hash, c.size AS content_size").
+		Join("JOIN contents AS c ON c.hash = i.hash")
+}
+
+// whereNotExpired hides items that have expired by time or paste count, pinned items are never hidden.
+func whereNotExpired(query *bun.SelectQuery, now time.Time) *bun.SelectQuery {
+	return query.Where(
+		"(i.pinned_at IS NOT NULL OR ((i.expires_at IS NULL OR i.expires_at > ?) AND (i.max_pastes IS NULL OR i.paste_count < i.max_pastes)))",
+		formatTimestamp(now),
+	)
+}
+
+// whereMimetype filters items by media type.
+// A filter without parameters matches the type regardless of parameters ("text/plain" also matches "text/plain; charset=utf-8"), one with parameters only matches exactly,
+// "type/*" matches every subtype, and "*/*" matches everything.
+func whereMimetype(query *bun.SelectQuery, filter string) *bun.SelectQuery {
+	if filter == "*/*" {
+		return query
+	}
+
+	if prefix, ok := strings.CutSuffix(filter, "/*"); ok {
+		prefix = strings.ToLower(prefix) + "/"
+		return query.Where("substr(i.mimetype, 1, ?) = ?", len(prefix), prefix)
+	}
+
+	mediaType, err := mimetype.Parse(filter)
+	if err != nil {
+		return query.Where("i.mimetype = ?", filter)
+	}
+
+	if len(mediaType.Params) > 0 {
+		return query.Where("i.mimetype = ?", mediaType.String())
+	}
+
+	essence := mediaType.Essence()
+	return query.Where("(i.mimetype = ? OR substr(i.mimetype, 1, ?) = ?)", essence, len(essence)+1, essence+";")
+}
+
+// formatTimestamp formats a time in the same layout SQLite's CURRENT_TIMESTAMP uses, so range comparisons on the stored text columns are correct.
+func formatTimestamp(t time.Time) string {
+	return t.UTC().Format(time.DateTime)
 }
 
 var _ ItemRepository = (*itemRepository)(nil)
+
+// ResolveID implements ItemRepository.
+func (i *itemRepository) ResolveID(ctx context.Context, prefix string) (string, error) {
+	return resolveIDPrefix(ctx, i.db, "items", prefix)
+}
+
+// ShortestPrefixes implements ItemRepository.
+func (i *itemRepository) ShortestPrefixes(
+	ctx context.Context,
+	ids []string,
+) (map[string]string, error) {
+	return shortestIDPrefixes(ctx, i.db, "items", ids)
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/uptrace/bun"
+	"go.trulyao.dev/bore/v2/pkg/errs"
+)
+
+const (
+	// MinPrefixLength is the shortest ID prefix that is resolved, shorter identifiers are only matched exactly.
+	MinPrefixLength = 4
+
+	idLength = 26
+
+	// maxPrefixCandidates is the number of candidates listed when a prefix is ambiguous.
+	maxPrefixCandidates = 5
+)
+
+// resolveIDPrefix returns the full ID of the only record in the table whose ID starts with the prefix.
+// Full IDs and identifiers that cannot be a prefix are returned as-is, an empty string is returned if nothing matches.
+func resolveIDPrefix(ctx context.Context, db bun.IDB, table string, prefix string) (string, error) {
+	return resolveColumnPrefix(ctx, db, table, "id", prefix)
+}
+
+// resolveColumnPrefix is resolveIDPrefix for tables where the ID is stored in another column, possibly more than once.
+func resolveColumnPrefix(
+	ctx context.Context,
+	db bun.IDB,
+	table string,
+	column string,
+	prefix string,
+) (string, error) {
+	prefix = strings.TrimSpace(prefix)
+	if len(prefix) < MinPrefixLength || len(prefix) >= idLength || !isAlphanumeric(prefix) {
+		return prefix, nil
+	}
+
+	// IDs are ULIDs, which are stored in upper case.
+	prefix = strings.ToUpper(prefix)
+
+	var ids []string
+	err := db.NewSelect().
+		Table(table).
+		Distinct().
+		ColumnExpr("?", bun.Ident(column)).
+		Where("? LIKE ?", bun.Ident(column), prefix+"%").
+		OrderExpr("? ASC", bun.Ident(column)).
+		Limit(maxPrefixCandidates+1).
+		Scan(ctx, &ids)
+	if err != nil {
+		return "", errs.Wrap(err, "failed to resolve id prefix")
+	}
+
+	switch len(ids) {
+	case 0:
+		return "", nil
+	case 1:
+		return ids[0], nil
+	}
+
+	candidates := ids[:min(len(ids), maxPrefixCandidates)]
+	if len(ids) > maxPrefixCandidates {
+		candidates = append(candidates, "...")
+	}
+
+	return "", &errs.AmbiguousIDError{Prefix: prefix, Candidates: candidates}
+}
+
+// shortestIDPrefixes returns the shortest prefix of each ID that is unique in the table, but never shorter than MinPrefixLength.
+func shortestIDPrefixes(
+	ctx context.Context,
+	db bun.IDB,
+	table string,
+	ids []string,
+) (map[string]string, error) {
+	prefixes := make(map[string]string, len(ids))
+	if len(ids) == 0 {
+		return prefixes, nil
+	}
+
+	// Only the IDs sorted right before and after an ID can share a longer prefix with it.
+	var neighbours []struct {
+		ID       string         `bun:"id"`
+		Previous sql.NullString `bun:"previous"`
+		Next     sql.NullString `bun:"next"`
+	}
+
+	err := db.NewSelect().
+		With("neighbours", db.NewSelect().
+			Table(table).
+			Column("id").
+			ColumnExpr("LAG(id) OVER (ORDER BY id) AS previous").
+			ColumnExpr("LEAD(id) OVER (ORDER BY id) AS next")).
+		Table("neighbours").
+		Column("id", "previous", "next").
+		Where("id IN (?)", bun.In(ids)).
+		Scan(ctx, &neighbours)
+	if err != nil {
+		return nil, errs.Wrap(err, "failed to find neighbouring ids")
+	}
+
+	for _, n := range neighbours {
+		length := max(
+			commonPrefixLength(n.ID, n.Previous.String),
+			commonPrefixLength(n.ID, n.Next.String),
+		) + 1
+		prefixes[n.ID] = n.ID[:min(max(length, MinPrefixLength), len(n.ID))]
+	}
+
+	return prefixes, nil
+}
+
+func commonPrefixLength(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+func isAlphanumeric(value string) bool {
+	for _, r := range value {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
@@ -3,9 +3,12 @@ package repository
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/uptrace/bun"
 	"go.trulyao.dev/bore/v2/database/models"
+	"go.trulyao.dev/bore/v2/pkg/blobstore"
+	"go.trulyao.dev/bore/v2/pkg/encryption"
 	"go.trulyao.dev/bore/v2/pkg/errs"
 )
 
@@ -19,11 +22,81 @@ type ItemRepository interface {
 		identifier string,
 		sequenceId int64,
 	) error // Bump updates the sequence ID and updated_at timestamp of an item to move it to the top of the list.
+	// SetLimits replaces the expiry (if not zero) and the paste limit (if positive) of an item, a new paste limit also resets its paste count.
+	SetLimits(ctx context.Context, tx bun.Tx, identifier string, expiresAt time.Time, maxPastes int) error
+	// MarkSensitive flags the item as sensitive, items are never unmarked.
+	MarkSensitive(ctx context.Context, tx bun.Tx, identifier string) error
 	DeleteById(ctx context.Context, tx bun.Tx, identifier string) error
+	// DeleteUnpinnedById deletes the item unless it is pinned, in which case errs.ErrItemPinned is returned.
+	DeleteUnpinnedById(ctx context.Context, tx bun.Tx, identifier string) error
+	Pin(ctx context.Context, tx bun.Tx, identifier string, pinnedAt time.Time) error
+	Unpin(ctx context.Context, tx bun.Tx, identifier string) error
+	IncrementPasteCount(ctx context.Context, tx bun.Tx, identifier string) error
+	// Move moves an item to another collection (uncategorized if empty).
+	// If the target already has an item with the same content, the moved item is merged into it and the ID of that item is returned.
+	Move(ctx context.Context, tx bun.Tx, identifier string, collectionID string) (mergedInto string, err error)
+	// UpdateContent points the item at the stored content with the given checksum and moves it to the top of the history.
+	// If its collection already has an item with the new content, the item is merged into it the same way as in Move.
+	UpdateContent(
+		ctx context.Context,
+		tx bun.Tx,
+		identifier string,
+		hash string,
+		sequenceID int64,
+		updatedAt time.Time,
+	) (mergedInto string, err error)
+	// AppendContent stores the content of an item with the suffix appended and returns it, see UpdateContent.
+	AppendContent(
+		ctx context.Context,
+		tx bun.Tx,
+		identifier string,
+		suffix []byte,
+		sequenceID int64,
+		updatedAt time.Time,
+	) (content []byte, mergedInto string, err error)
 
+	// FindLatest, FindNthLatest and FindById ignore items that have expired (by time or paste count) but have not been deleted yet.
 	FindLatest(ctx context.Context, collectionID string) (*models.Item, error)
+	// FindNthLatest returns the item at the given offset in the order used by FindLatest, so offset 0 is the latest item.
+	FindNthLatest(ctx context.Context, collectionID string, offset int) (*models.Item, error)
+	// FindById also accepts a unique ID prefix, see ResolveID.
 	FindById(ctx context.Context, identifier string, collectionId string) (*models.Item, error)
 	FindByHash(ctx context.Context, hash string, collectionId string) (*models.Item, error)
+	// FindAll returns items matching the given filters, most recently applied first.
+	FindAll(ctx context.Context, opts FindItemsOptions) (models.Items, error)
+	// CountPinned returns the number of pinned items in the collection.
+	CountPinned(ctx context.Context, collectionID string) (int, error)
+	// FindUnpinnedSummaries returns summaries of every unpinned item in the collection (or uncategorized items if empty), most recent first.
+	FindUnpinnedSummaries(ctx context.Context, collectionID string) ([]models.ItemSummary, error)
+	// FindExpired returns summaries of every unpinned item that has expired by time or paste count as of now.
+	FindExpired(ctx context.Context, now time.Time) ([]models.ItemSummary, error)
+
+	// ResolveID returns the full ID of the item whose ID starts with the prefix, or an empty string if there is none.
+	// Full IDs and identifiers that cannot be a prefix are returned unchanged.
+	// An *errs.AmbiguousIDError is returned if more than one item matches.
+	ResolveID(ctx context.Context, prefix string) (string, error)
+	// ShortestPrefixes returns the shortest unique prefix of each of the given item IDs.
+	ShortestPrefixes(ctx context.Context, ids []string) (map[string]string, error)
+}
+
+// FindItemsOptions filters and paginates item listings.
+// Zero values are ignored, so an empty struct matches every item.
+type FindItemsOptions struct {
+	CollectionID  string    // Only items in this collection, all collections if empty.
+	Mimetype      string    // Only items with this mimetype, see whereMimetype.
+	CreatedAfter  time.Time // Only items created at or after this time.
+	CreatedBefore time.Time // Only items created before this time.
+	UpdatedAfter  time.Time // Only items updated at or after this time.
+	UpdatedBefore time.Time // Only items updated before this time.
+
+	// PinnedFirst orders pinned items before all others.
+	PinnedFirst bool
+
+	// BeforeSequenceID is the keyset cursor, only items with a lower last_applied_sequence_id are returned.
+	BeforeSequenceID int64
+	// BeforePinned must be set when the cursor item is pinned and PinnedFirst is used, since pinned and unpinned items are paginated as two separate runs.
+	BeforePinned bool
+	Limit        int
 }
 
 type CollectionLookupOptions struct {
@@ -53,9 +126,102 @@ type CollectionRepository interface {
 
 	FindById(ctx context.Context, identifier string) (*models.Collection, error)
 	FindByName(ctx context.Context, name string) (*models.Collection, error)
-	// FindOne looks up a collection by either ID or name.
+	// FindOne looks up a collection by either ID or name, falling back to treating the identifier as a unique ID prefix.
 	FindOne(ctx context.Context, opts CollectionLookupOptions) (*models.Collection, error)
 	FindAll(ctx context.Context, opts FindAllOptions) (models.Collections, error)
+
+	// ResolveID returns the full ID of the collection whose ID starts with the prefix, or an empty string if there is none.
+	// Full IDs and identifiers that cannot be a prefix are returned unchanged.
+	// An *errs.AmbiguousIDError is returned if more than one collection matches.
+	ResolveID(ctx context.Context, prefix string) (string, error)
+	// ShortestPrefixes returns the shortest unique prefix of each of the given collection IDs.
+	ShortestPrefixes(ctx context.Context, ids []string) (map[string]string, error)
+}
+
+type SearchOptions struct {
+	Query          string // Free-form search terms, every term must match.
+	CollectionID   string // Only search items in this collection, all collections if empty.
+	HighlightStart string // Inserted before every matched term in the snippet.
+	HighlightEnd   string // Inserted after every matched term in the snippet.
+	Limit          int
+}
+
+// SearchRepository maintains the full-text index over item contents.
+// The index is written to by the item projections, so it is always in sync with the items table.
+type SearchRepository interface {
+	Index(ctx context.Context, tx bun.Tx, itemID string, content []byte) error
+	Remove(ctx context.Context, tx bun.Tx, itemID string) error
+	// RemoveByCollection removes all items in the collection from the index, it must be called before the items are deleted.
+	RemoveByCollection(ctx context.Context, tx bun.Tx, collectionID string) error
+	// Optimize merges the index segments, removed items otherwise leave their terms behind until the segments are merged on their own.
+	Optimize(ctx context.Context, tx bun.Tx) error
+
+	Search(ctx context.Context, opts SearchOptions) (models.ItemSearchResults, error)
+}
+
+// UndoRepository stores the undo stack, entries that have been undone make up the redo stack.
+type UndoRepository interface {
+	// Push records a new entry and discards the redo stack, since redoing past a new operation would apply events out of order.
+	Push(ctx context.Context, tx bun.Tx, entry *models.UndoEntry) error
+	// FindLastDone returns the most recent entry that has not been undone, or nil if there is none.
+	FindLastDone(ctx context.Context) (*models.UndoEntry, error)
+	// FindLastUndone returns the entry that was undone most recently, or nil if there is none.
+	FindLastUndone(ctx context.Context) (*models.UndoEntry, error)
+	SetUndone(ctx context.Context, tx bun.Tx, id int64, undoneAt bun.NullTime) error
+	// RemoveForAggregate removes every entry with an undo or redo event on the aggregate, since those events may carry its content.
+	RemoveForAggregate(ctx context.Context, tx bun.Tx, aggregateID string) error
+}
+
+// TrashRepository keeps deleted items and collections until they are restored or expire.
+// Rows are moved into the trash by the delete projections and moved back by the restore projections.
+type TrashRepository interface {
+	// TrashItem copies the item into the trash, it must be called before the item is deleted.
+	TrashItem(ctx context.Context, tx bun.Tx, itemID string, sequenceID int64, deletedAt time.Time) error
+	// TrashCollection copies the collection and all of its items into the trash under the same sequence ID.
+	TrashCollection(ctx context.Context, tx bun.Tx, collectionID string, sequenceID int64, deletedAt time.Time) error
+	// RestoreItem moves the most recently trashed copy of the item back to its original place in the history, it returns nil if there was nothing to restore.
+	// The item becomes uncategorized if its collection no longer exists, and is dropped if the same content already exists there.
+	RestoreItem(ctx context.Context, tx bun.Tx, itemID string) (*models.Item, error)
+	// RestoreCollection moves the collection back along with the items that were deleted with it, and returns the restored items.
+	RestoreCollection(ctx context.Context, tx bun.Tx, collectionID string) (models.Items, error)
+
+	// PurgeItem permanently removes every trashed copy of the item, including copies deleted along with a collection.
+	PurgeItem(ctx context.Context, tx bun.Tx, itemID string) error
+
+	// FindAll returns every trashed item and collection, most recently deleted first.
+	// Items deleted together with their collection are only counted in the collection's ItemsCount.
+	FindAll(ctx context.Context) (models.TrashEntries, error)
+	// FindLatest returns the most recent trash entry of the item or collection, or nil if there is none.
+	FindLatest(ctx context.Context, aggregateID string) (*models.TrashEntry, error)
+	// ResolveID returns the full ID of the trashed item or collection whose ID starts with the prefix, see ItemRepository.ResolveID.
+	ResolveID(ctx context.Context, prefix string) (string, error)
+	// DeleteBefore permanently removes entries deleted before the given time, or every entry if it is zero.
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// ContentRepository stores every unique content once, items, trash entries, events and undo entries reference it by hash.
+// Hashes are passed in and out as plaintext checksums, they are only keyed in the database, see encryption.Cipher.Hash.
+type ContentRepository interface {
+	// Put stores the content under its checksum (content.Hash) unless the same content is already stored.
+	Put(ctx context.Context, tx bun.Tx, content *models.Content) error
+	// Find returns the content with the given checksum, or nil if there is none.
+	// Pass the transaction as db to find content that was stored in it.
+	Find(ctx context.Context, db bun.IDB, checksum string) (*models.Content, error)
+	// DeleteUnreferenced removes every content that is no longer referenced, and returns how many were removed.
+	DeleteUnreferenced(ctx context.Context, tx bun.Tx) (int64, error)
+	// FindBlobs returns the hash of every blob in the blob store that a content is kept in.
+	FindBlobs(ctx context.Context) (map[string]struct{}, error)
+}
+
+// EncryptionRepository stores the description of the encryption key and re-encrypts existing data when it changes.
+type EncryptionRepository interface {
+	// Find returns the description of the current key, or nil if encryption is disabled.
+	Find(ctx context.Context) (*models.Encryption, error)
+	// Rekey decrypts every content, event payload and undo entry with one cipher and encrypts it with another, then records the new key.
+	// The from cipher is nil when the data is not encrypted yet, and the full-text index is cleared since it would hold the plaintext.
+	// Blobs are never encrypted, so contents kept in the blob store are read back into their rows.
+	// Payloads that still carry their content inline (see payload.CreateItem) are moved to the contents table along the way.
+	Rekey(ctx context.Context, tx bun.Tx, from, to *encryption.Cipher, key *models.Encryption, blobs *blobstore.Store) error
 }
 
 // Repository is the main interface for accessing all repositories.
@@ -63,25 +229,40 @@ type CollectionRepository interface {
 type Repository interface {
 	Items() ItemRepository
 	Collections() CollectionRepository
+	Search() SearchRepository
+	Undo() UndoRepository
+	Trash() TrashRepository
+	Encryption() EncryptionRepository
+	Contents() ContentRepository
+
+	// Cipher returns the cipher contents and payloads are encrypted with, or nil if encryption is disabled.
+	Cipher() *encryption.Cipher
 }
 
 type repo struct {
-	mu sync.Mutex
-	db *bun.DB
+	mu     sync.Mutex
+	db     *bun.DB
+	cipher *encryption.Cipher
 
 	items       ItemRepository
 	collections CollectionRepository
+	search      SearchRepository
+	undo        UndoRepository
+	trash       TrashRepository
+	encryption  EncryptionRepository
+	contents    ContentRepository
 }
 
-func NewRepository(db *bun.DB) Repository {
-	return &repo{db: db}
+// NewRepository creates a repository, the cipher is nil unless the database is encrypted.
+func NewRepository(db *bun.DB, cipher *encryption.Cipher) Repository {
+	return &repo{db: db, cipher: cipher}
 }
 
 // Items implements Repository.
 func (r *repo) Items() ItemRepository {
 	return withLock(r, func(r *repo) ItemRepository {
 		if r.items == nil {
-			r.items = &itemRepository{db: r.db}
+			r.items = &itemRepository{db: r.db, cipher: r.cipher}
 		}
 		return r.items
 	})
@@ -97,6 +278,61 @@ func (r *repo) Collections() CollectionRepository {
 	})
 }
 
+// Search implements Repository.
+func (r *repo) Search() SearchRepository {
+	return withLock(r, func(r *repo) SearchRepository {
+		if r.search == nil {
+			r.search = &searchRepository{db: r.db, cipher: r.cipher}
+		}
+		return r.search
+	})
+}
+
+// Undo implements Repository.
+func (r *repo) Undo() UndoRepository {
+	return withLock(r, func(r *repo) UndoRepository {
+		if r.undo == nil {
+			r.undo = &undoRepository{db: r.db, cipher: r.cipher}
+		}
+		return r.undo
+	})
+}
+
+// Trash implements Repository.
+func (r *repo) Trash() TrashRepository {
+	return withLock(r, func(r *repo) TrashRepository {
+		if r.trash == nil {
+			r.trash = &trashRepository{db: r.db, cipher: r.cipher}
+		}
+		return r.trash
+	})
+}
+
+// Encryption implements Repository.
+func (r *repo) Encryption() EncryptionRepository {
+	return withLock(r, func(r *repo) EncryptionRepository {
+		if r.encryption == nil {
+			r.encryption = &encryptionRepository{db: r.db}
+		}
+		return r.encryption
+	})
+}
+
+// Contents implements Repository.
+func (r *repo) Contents() ContentRepository {
+	return withLock(r, func(r *repo) ContentRepository {
+		if r.contents == nil {
+			r.contents = &contentRepository{db: r.db, cipher: r.cipher}
+		}
+		return r.contents
+	})
+}
+
+// Cipher implements Repository.
+func (r *repo) Cipher() *encryption.Cipher {
+	return r.cipher
+}
+
 func withLock[T any](r *repo, fn func(*repo) T) T {
 	r.mu.Lock()
 	defer r.mu.Unlock()
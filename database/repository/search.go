@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/uptrace/bun"
+	"go.trulyao.dev/bore/v2/database/models"
+	"go.trulyao.dev/bore/v2/pkg/encryption"
+	"go.trulyao.dev/bore/v2/pkg/errs"
+)
+
+// snippetTokens is the maximum number of tokens included in a search snippet.
+const snippetTokens = 12
+
+// searchRepository uses the FTS index, except when contents are encrypted, since the index would hold them in plaintext.
+// Encrypted contents are searched by decrypting and scanning every item instead.
+type searchRepository struct {
+	db     *bun.DB
+	cipher *encryption.Cipher
+}
+
+// Index implements SearchRepository.
+func (s *searchRepository) Index(
+	ctx context.Context,
+	tx bun.Tx,
+	itemID string,
+	content []byte,
+) error {
+	if s.cipher.Enabled() {
+		return nil
+	}
+
+	itemID = strings.TrimSpace(itemID)
+	if itemID == "" {
+		return ErrEmptyIdentifier
+	}
+
+	// Replace any existing entry so re-applying a projection does not index the same item twice.
+	if err := s.Remove(ctx, tx, itemID); err != nil {
+		return err
+	}
+
+	// Binary content has no words to search for, indexing it would only fill the index with noise.
+	if !utf8.Valid(content) {
+		return nil
+	}
+
+	// The item insert is ignored on conflicts, so only index items that actually made it into the table.
+	// Sensitive items are never indexed, so they can not show up in search results (or linger in the index).
+	// Neither are items in the blob store, their content is too large to be worth indexing.
+	_, err := tx.NewRaw(
+		`INSERT INTO items_fts (content, item_id) SELECT ?, ? WHERE EXISTS (
+			SELECT 1 FROM items AS i JOIN contents AS c ON c.hash = i.hash
+			WHERE i.id = ? AND NOT i.sensitive AND c.blob_hash IS NULL
+		)`,
+		string(content),
+		itemID,
+		itemID,
+	).Exec(ctx)
+	return err
+}
+
+// Remove implements SearchRepository.
+func (s *searchRepository) Remove(ctx context.Context, tx bun.Tx, itemID string) error {
+	itemID = strings.TrimSpace(itemID)
+	if itemID == "" {
+		return ErrEmptyIdentifier
+	}
+
+	_, err := tx.NewRaw("DELETE FROM items_fts WHERE item_id = ?", itemID).Exec(ctx)
+	return err
+}
+
+// RemoveByCollection implements SearchRepository.
+func (s *searchRepository) RemoveByCollection(
+	ctx context.Context,
+	tx bun.Tx,
+	collectionID string,
+) error {
+	collectionID = strings.TrimSpace(collectionID)
+	if collectionID == "" {
+		return ErrEmptyIdentifier
+	}
+
+	_, err := tx.NewRaw(
+		"DELETE FROM items_fts WHERE item_id IN (SELECT id FROM items WHERE collection_id = ?)",
+		collectionID,
+	).Exec(ctx)
+	return err
+}
+
+// Optimize implements SearchRepository.
+func (s *searchRepository) Optimize(ctx context.Context, tx bun.Tx) error {
+	_, err := tx.NewRaw("INSERT INTO items_fts (items_fts) VALUES ('optimize')").Exec(ctx)
+	return err
+}
+
+// Search implements SearchRepository.
+func (s *searchRepository) Search(
+	ctx context.Context,
+	opts SearchOptions,
+) (models.ItemSearchResults, error) {
+	match := BuildMatchExpression(opts.Query)
+	if match == "" {
+		return nil, errs.New("search query cannot be empty")
+	}
+
+	if s.cipher.Enabled() {
+		return s.scan(ctx, opts)
+	}
+
+	var results models.ItemSearchResults
+	query := withContent(s.db.NewSelect().Model(&results)).
+		Relation("Collection").
+		Join("JOIN items_fts AS f ON f.item_id = i.id").
+		ColumnExpr(
+			"snippet(items_fts, 0, ?, ?, ?, ?) AS snippet",
+			opts.HighlightStart,
+			opts.HighlightEnd,
+			"…",
+			snippetTokens,
+		).
+		ColumnExpr("f.rank AS rank").
+		Where("items_fts MATCH ?", match).
+		OrderExpr("f.rank ASC").
+		Order("i.last_applied_sequence_id DESC")
+
+	whereNotExpired(query, time.Now())
+
+	if collectionID := strings.TrimSpace(opts.CollectionID); collectionID != "" {
+		query.Where("i.collection_id = ?", collectionID)
+	}
+
+	if opts.Limit > 0 {
+		query.Limit(opts.Limit)
+	}
+
+	if err := query.Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// scan matches the decrypted content of every item against the query, with the same semantics as the FTS match expression.
+// Results are ordered by recency since there is no relevance ranking.
+func (s *searchRepository) scan(
+	ctx context.Context,
+	opts SearchOptions,
+) (models.ItemSearchResults, error) {
+	items, err := (&itemRepository{db: s.db, cipher: s.cipher}).FindAll(
+		ctx,
+		FindItemsOptions{CollectionID: opts.CollectionID},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := strings.Fields(strings.ToLower(opts.Query))
+
+	var results models.ItemSearchResults
+	for _, item := range items {
+		if item.Sensitive {
+			continue
+		}
+
+		snippet, ok := scanSnippet(string(item.Content), terms, opts.HighlightStart, opts.HighlightEnd)
+		if !ok {
+			continue
+		}
+
+		results = append(results, &models.ItemSearchResult{Item: *item, Snippet: snippet})
+		if opts.Limit > 0 && len(results) == opts.Limit {
+			break
+		}
+	}
+
+	return results, nil
+}
+
+// scanSnippet reports whether every term occurs in the content, and builds a snippet of up to snippetTokens words around the first match.
+func scanSnippet(content string, terms []string, highlightStart, highlightEnd string) (string, bool) {
+	lower := strings.ToLower(content)
+	for _, term := range terms {
+		if !strings.Contains(lower, term) {
+			return "", false
+		}
+	}
+
+	words := strings.Fields(content)
+	matches := func(word string) bool {
+		word = strings.ToLower(word)
+		for _, term := range terms {
+			if strings.Contains(word, term) {
+				return true
+			}
+		}
+		return false
+	}
+
+	first := 0
+	for idx, word := range words {
+		if matches(word) {
+			first = idx
+			break
+		}
+	}
+
+	start := max(first-snippetTokens/2, 0)
+	end := min(start+snippetTokens, len(words))
+
+	snippet := make([]string, 0, end-start+2)
+	if start > 0 {
+		snippet = append(snippet, "…")
+	}
+	for _, word := range words[start:end] {
+		if matches(word) {
+			word = highlightStart + word + highlightEnd
+		}
+		snippet = append(snippet, word)
+	}
+	if end < len(words) {
+		snippet = append(snippet, "…")
+	}
+
+	return strings.Join(snippet, " "), true
+}
+
+// BuildMatchExpression converts free-form user input into an FTS5 MATCH expression.
+// Every term is quoted so punctuation in the input (e.g. "foo-bar", "a.b") can not be misread as query syntax, and the last term is matched as a prefix so results show up while the user is still typing.
+func BuildMatchExpression(input string) string {
+	terms := strings.Fields(input)
+	if len(terms) == 0 {
+		return ""
+	}
+
+	quoted := make([]string, 0, len(terms))
+	for _, term := range terms {
+		quoted = append(quoted, `"`+strings.ReplaceAll(term, `"`, `""`)+`"`)
+	}
+	quoted[len(quoted)-1] += "*"
+
+	return strings.Join(quoted, " ")
+}
+
+var _ SearchRepository = (*searchRepository)(nil)
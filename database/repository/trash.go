@@ -0,0 +1,369 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/uptrace/bun"
+	"go.trulyao.dev/bore/v2/database/models"
+	"go.trulyao.dev/bore/v2/pkg/encryption"
+	"go.trulyao.dev/bore/v2/pkg/errs"
+	"go.trulyao.dev/bore/v2/pkg/events/aggregate"
+	"go.trulyao.dev/bore/v2/pkg/lib"
+)
+
+// trashRepository copies rows between the items and trash tables as-is, trashed items keep referencing their content by its keyed hash.
+type trashRepository struct {
+	db     *bun.DB
+	cipher *encryption.Cipher
+}
+
+// TrashItem implements TrashRepository.
+func (t *trashRepository) TrashItem(
+	ctx context.Context,
+	tx bun.Tx,
+	itemID string,
+	sequenceID int64,
+	deletedAt time.Time,
+) error {
+	item := new(models.Item)
+	err := tx.NewSelect().Model(item).Where("id = ?", itemID).Limit(1).Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	_, err = tx.NewInsert().Model(itemTrashEntry(item, sequenceID, deletedAt)).Exec(ctx)
+	return err
+}
+
+// TrashCollection implements TrashRepository.
+func (t *trashRepository) TrashCollection(
+	ctx context.Context,
+	tx bun.Tx,
+	collectionID string,
+	sequenceID int64,
+	deletedAt time.Time,
+) error {
+	collection := new(models.Collection)
+	err := tx.NewSelect().Model(collection).Where("id = ?", collectionID).Limit(1).Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	var items models.Items
+	if err := tx.NewSelect().Model(&items).Where("collection_id = ?", collectionID).Scan(ctx); err != nil {
+		return err
+	}
+
+	entries := make([]*models.TrashEntry, 0, len(items)+1)
+	entries = append(entries, &models.TrashEntry{
+		AggregateType: aggregate.AggregateTypeCollection.String(),
+		AggregateID:   collection.ID,
+		SequenceID:    sequenceID,
+		Name:          sql.NullString{String: collection.Name, Valid: true},
+		CreatedAt:     collection.CreatedAt,
+		DeletedAt:     deletedAt,
+	})
+
+	for _, item := range items {
+		entries = append(entries, itemTrashEntry(item, sequenceID, deletedAt))
+	}
+
+	_, err = tx.NewInsert().Model(&entries).Exec(ctx)
+	return err
+}
+
+// RestoreItem implements TrashRepository.
+func (t *trashRepository) RestoreItem(
+	ctx context.Context,
+	tx bun.Tx,
+	itemID string,
+) (*models.Item, error) {
+	entry, err := t.findLatest(ctx, tx, aggregate.AggregateTypeItem.String(), itemID)
+	if err != nil || entry == nil {
+		return nil, err
+	}
+
+	item, err := t.restoreItem(ctx, tx, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.NewDelete().Model((*models.TrashEntry)(nil)).Where("id = ?", entry.ID).Exec(ctx)
+	return item, err
+}
+
+// RestoreCollection implements TrashRepository.
+func (t *trashRepository) RestoreCollection(
+	ctx context.Context,
+	tx bun.Tx,
+	collectionID string,
+) (models.Items, error) {
+	entry, err := t.findLatest(ctx, tx, aggregate.AggregateTypeCollection.String(), collectionID)
+	if err != nil || entry == nil {
+		return nil, err
+	}
+
+	collection := &models.Collection{
+		ID:        entry.AggregateID,
+		Name:      entry.Name.String,
+		CreatedAt: entry.CreatedAt,
+	}
+	if _, err := tx.NewInsert().Model(collection).Ignore().Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	var itemEntries models.TrashEntries
+	err = tx.NewSelect().
+		Model(&itemEntries).
+		Where("aggregate_type = ?", aggregate.AggregateTypeItem.String()).
+		Where("sequence_id = ?", entry.SequenceID).
+		Where("collection_id = ?", collectionID).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	restored := make(models.Items, 0, len(itemEntries))
+	for _, itemEntry := range itemEntries {
+		item, err := t.restoreItem(ctx, tx, itemEntry)
+		if err != nil {
+			return nil, err
+		}
+
+		if item != nil {
+			restored = append(restored, item)
+		}
+	}
+
+	_, err = tx.NewDelete().
+		Model((*models.TrashEntry)(nil)).
+		Where("id = ?", entry.ID).
+		WhereOr("aggregate_type = ? AND sequence_id = ? AND collection_id = ?",
+			aggregate.AggregateTypeItem.String(), entry.SequenceID, collectionID).
+		Exec(ctx)
+
+	return restored, err
+}
+
+// PurgeItem implements TrashRepository.
+func (t *trashRepository) PurgeItem(ctx context.Context, tx bun.Tx, itemID string) error {
+	_, err := tx.NewDelete().
+		Model((*models.TrashEntry)(nil)).
+		Where("aggregate_type = ? AND aggregate_id = ?", aggregate.AggregateTypeItem.String(), itemID).
+		Exec(ctx)
+	return err
+}
+
+// FindAll implements TrashRepository.
+func (t *trashRepository) FindAll(ctx context.Context) (models.TrashEntries, error) {
+	var entries models.TrashEntries
+
+	// Items deleted together with their collection are listed as part of the collection.
+	err := withTrashedContent(t.db.NewSelect().Model(&entries)).
+		ColumnExpr(`(SELECT COUNT(*) FROM trash AS ti
+			WHERE t.aggregate_type = 'collection' AND ti.aggregate_type = 'item'
+			AND ti.sequence_id = t.sequence_id AND ti.collection_id = t.aggregate_id) AS items_count`).
+		Where(`NOT (t.aggregate_type = 'item' AND EXISTS (SELECT 1 FROM trash AS tc
+			WHERE tc.aggregate_type = 'collection' AND tc.sequence_id = t.sequence_id
+			AND tc.aggregate_id = t.collection_id))`).
+		Order("t.deleted_at DESC", "t.id DESC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if err := t.open(entry); err != nil {
+			return nil, err
+		}
+	}
+
+	return entries, nil
+}
+
+// FindLatest implements TrashRepository.
+func (t *trashRepository) FindLatest(ctx context.Context, aggregateID string) (*models.TrashEntry, error) {
+	entry := new(models.TrashEntry)
+	err := withTrashedContent(t.db.NewSelect().Model(entry)).
+		Where("t.aggregate_id = ?", aggregateID).
+		Order("t.id DESC").
+		Limit(1).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return entry, t.open(entry)
+}
+
+// ResolveID implements TrashRepository.
+func (t *trashRepository) ResolveID(ctx context.Context, prefix string) (string, error) {
+	return resolveColumnPrefix(ctx, t.db, "trash", "aggregate_id", prefix)
+}
+
+// DeleteBefore implements TrashRepository.
+func (t *trashRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	query := t.db.NewDelete().Model((*models.TrashEntry)(nil))
+	if before.IsZero() {
+		query.Where("1 = 1")
+	} else {
+		query.Where("deleted_at < ?", formatTimestamp(before))
+	}
+
+	result, err := query.Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (t *trashRepository) findLatest(
+	ctx context.Context,
+	db bun.IDB,
+	aggregateType string,
+	aggregateID string,
+) (*models.TrashEntry, error) {
+	entry := new(models.TrashEntry)
+	err := db.NewSelect().
+		Model(entry).
+		Where("aggregate_type = ? AND aggregate_id = ?", aggregateType, aggregateID).
+		Order("id DESC").
+		Limit(1).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// restoreItem inserts a trashed item back into the items table.
+// Items whose collection no longer exists become uncategorized, and nothing is restored if the same content already exists there.
+func (t *trashRepository) restoreItem(
+	ctx context.Context,
+	tx bun.Tx,
+	entry *models.TrashEntry,
+) (*models.Item, error) {
+	collectionID := entry.CollectionID
+	if collectionID.Valid {
+		exists, err := tx.NewSelect().
+			Model((*models.Collection)(nil)).
+			Where("id = ?", collectionID.String).
+			Exists(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if !exists {
+			collectionID = sql.NullString{}
+		}
+	}
+
+	// The unique index does not cover uncategorized items, so duplicates are checked for explicitly.
+	duplicate := tx.NewSelect().Model((*models.Item)(nil)).Where("hash = ?", entry.Hash.String)
+	if collectionID.Valid {
+		duplicate.Where("collection_id = ?", collectionID.String)
+	} else {
+		duplicate.Where("collection_id IS NULL")
+	}
+
+	exists, err := duplicate.Exists(ctx)
+	if err != nil || exists {
+		return nil, err
+	}
+
+	item := &models.Item{
+		ID:                    entry.AggregateID,
+		Hash:                  entry.Hash.String,
+		Mimetype:              entry.Mimetype.String,
+		LastAppliedSequenceID: entry.LastAppliedSequenceID,
+		CreatedAt:             entry.CreatedAt,
+		UpdatedAt:             entry.UpdatedAt.Time,
+		PinnedAt:              entry.PinnedAt,
+		ExpiresAt:             entry.ExpiresAt,
+		MaxPastes:             entry.MaxPastes,
+		PasteCount:            entry.PasteCount,
+		Sensitive:             entry.Sensitive,
+		CollectionID:          collectionID,
+	}
+
+	result, err := tx.NewInsert().Model(item).Ignore().Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return nil, err
+	}
+
+	// Read the item back with its content, the restore projections index it.
+	restored := new(models.Item)
+	if err := withContent(tx.NewSelect().Model(restored)).Where("i.id = ?", item.ID).Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	return restored, (&itemRepository{db: t.db, cipher: t.cipher}).open(restored)
+}
+
+// open decrypts the content of a trashed item and restores its plaintext checksum, like itemRepository.open.
+func (t *trashRepository) open(entry *models.TrashEntry) error {
+	if !t.cipher.Enabled() || entry.Content == nil || entry.BlobHash != "" {
+		return nil
+	}
+
+	content, err := t.cipher.Open(entry.Content)
+	if err != nil {
+		return errs.Wrap(err, "failed to decrypt trashed item "+entry.AggregateID)
+	}
+
+	entry.Content = content
+	entry.Hash = sql.NullString{String: lib.ComputeChecksum(content), Valid: true}
+	return nil
+}
+
+func itemTrashEntry(item *models.Item, sequenceID int64, deletedAt time.Time) *models.TrashEntry {
+	return &models.TrashEntry{
+		AggregateType: aggregate.AggregateTypeItem.String(),
+		AggregateID:   item.ID,
+		SequenceID:    sequenceID,
+		CollectionID:  item.CollectionID,
+		Hash:          sql.NullString{String: item.Hash, Valid: true},
+		Mimetype:      sql.NullString{String: item.Mimetype, Valid: true},
+		PinnedAt:      item.PinnedAt,
+		ExpiresAt:     item.ExpiresAt,
+		MaxPastes:     item.MaxPastes,
+		PasteCount:    item.PasteCount,
+		Sensitive:     item.Sensitive,
+		CreatedAt:     item.CreatedAt,
+		UpdatedAt:     bun.NullTime{Time: item.UpdatedAt},
+
+		LastAppliedSequenceID: item.LastAppliedSequenceID,
+		DeletedAt:             deletedAt,
+	}
+}
+
+// withTrashedContent loads the content each trashed item references, collections have none.
+func withTrashedContent(query *bun.SelectQuery) *bun.SelectQuery {
+	return query.
+		ColumnExpr("t.*").
+		ColumnExpr("c.content, c.blob_hash, c.size AS content_size").
+		Join("LEFT JOIN contents AS c ON c.hash = t.hash")
+}
+
+var _ TrashRepository = (*trashRepository)(nil)
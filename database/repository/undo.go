@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/uptrace/bun"
+	"go.trulyao.dev/bore/v2/database/models"
+	"go.trulyao.dev/bore/v2/pkg/encryption"
+)
+
+// maxUndoEntries is the number of operations kept on the undo stack, older entries are discarded.
+const maxUndoEntries = 100
+
+// undoRepository encrypts the payloads of the stored events and keys their content hashes the same way the event store does.
+type undoRepository struct {
+	db     *bun.DB
+	cipher *encryption.Cipher
+}
+
+// Push implements UndoRepository.
+func (u *undoRepository) Push(ctx context.Context, tx bun.Tx, entry *models.UndoEntry) error {
+	_, err := tx.NewDelete().
+		Model((*models.UndoEntry)(nil)).
+		Where("undone_at IS NOT NULL").
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	row := *entry
+	if row.UndoEvents, err = u.sealEvents(entry.UndoEvents); err != nil {
+		return err
+	}
+	if row.RedoEvents, err = u.sealEvents(entry.RedoEvents); err != nil {
+		return err
+	}
+
+	if _, err := tx.NewInsert().Model(&row).Exec(ctx); err != nil {
+		return err
+	}
+	entry.ID = row.ID
+
+	_, err = tx.NewDelete().
+		Model((*models.UndoEntry)(nil)).
+		Where("id <= ?", entry.ID-maxUndoEntries).
+		Exec(ctx)
+	return err
+}
+
+// FindLastDone implements UndoRepository.
+func (u *undoRepository) FindLastDone(ctx context.Context) (*models.UndoEntry, error) {
+	entry := new(models.UndoEntry)
+	err := u.db.NewSelect().
+		Model(entry).
+		Where("undone_at IS NULL").
+		Order("id DESC").
+		Limit(1).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return entry, u.open(entry)
+}
+
+// FindLastUndone implements UndoRepository.
+func (u *undoRepository) FindLastUndone(ctx context.Context) (*models.UndoEntry, error) {
+	// Entries are undone newest first, so the oldest undone entry is the one that was undone last.
+	entry := new(models.UndoEntry)
+	err := u.db.NewSelect().
+		Model(entry).
+		Where("undone_at IS NOT NULL").
+		Order("id ASC").
+		Limit(1).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return entry, u.open(entry)
+}
+
+// SetUndone implements UndoRepository.
+func (u *undoRepository) SetUndone(
+	ctx context.Context,
+	tx bun.Tx,
+	id int64,
+	undoneAt bun.NullTime,
+) error {
+	_, err := tx.NewUpdate().
+		Model((*models.UndoEntry)(nil)).
+		Set("undone_at = ?", undoneAt).
+		Where("id = ?", id).
+		Exec(ctx)
+	return err
+}
+
+// RemoveForAggregate implements UndoRepository.
+func (u *undoRepository) RemoveForAggregate(ctx context.Context, tx bun.Tx, aggregateID string) error {
+	_, err := tx.NewDelete().
+		Model((*models.UndoEntry)(nil)).
+		Where(`EXISTS (SELECT 1 FROM json_each(u.undo_events) WHERE json_extract(value, '$.aggregate_id') = ?)
+			OR EXISTS (SELECT 1 FROM json_each(u.redo_events) WHERE json_extract(value, '$.aggregate_id') = ?)`,
+			aggregateID, aggregateID).
+		Exec(ctx)
+	return err
+}
+
+func (u *undoRepository) sealEvents(events []models.UndoEvent) ([]models.UndoEvent, error) {
+	sealed := make([]models.UndoEvent, len(events))
+	for i, event := range events {
+		payload, err := u.cipher.SealJSON(event.Payload)
+		if err != nil {
+			return nil, err
+		}
+
+		event.Payload = payload
+		if event.ContentHash != "" {
+			event.ContentHash = u.cipher.Hash(event.ContentHash)
+		}
+		sealed[i] = event
+	}
+
+	return sealed, nil
+}
+
+func (u *undoRepository) open(entry *models.UndoEntry) error {
+	for _, events := range [][]models.UndoEvent{entry.UndoEvents, entry.RedoEvents} {
+		for i := range events {
+			payload, err := u.cipher.OpenJSON(events[i].Payload)
+			if err != nil {
+				return err
+			}
+
+			events[i].Payload = payload
+		}
+	}
+
+	return nil
+}
+
+var _ UndoRepository = (*undoRepository)(nil)
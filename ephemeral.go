@@ -0,0 +1,45 @@
+package bore
+
+import (
+	"context"
+
+	"go.trulyao.dev/bore/v2/pkg/errs"
+	"go.trulyao.dev/bore/v2/pkg/lib"
+)
+
+// CopyEphemeral writes the data to the system clipboard only, nothing is recorded in the history and no secret detection is done.
+// It returns the checksum of the data for ClearEphemeral.
+func (i *clipboardNamespace) CopyEphemeral(ctx context.Context, data []byte) (string, error) {
+	if !i.clipboard.Available() {
+		return "", errs.ErrClipboardUnavailable
+	}
+
+	if err := i.clipboard.Write(ctx, data); err != nil {
+		return "", errs.Wrap(err, "failed to write to the system clipboard")
+	}
+
+	return lib.ComputeChecksum(data), nil
+}
+
+// ClearEphemeral clears the system clipboard if it still holds the content with the given checksum, so anything copied since is left alone.
+// It reports whether the clipboard was cleared.
+func (i *clipboardNamespace) ClearEphemeral(ctx context.Context, checksum string) (bool, error) {
+	if !i.clipboard.Available() {
+		return false, errs.ErrClipboardUnavailable
+	}
+
+	content, err := i.clipboard.Read(ctx)
+	if err != nil {
+		return false, errs.Wrap(err, "failed to read the system clipboard")
+	}
+
+	if lib.ComputeChecksum(content) != checksum {
+		return false, nil
+	}
+
+	if err := i.clipboard.Clear(ctx); err != nil {
+		return false, errs.Wrap(err, "failed to clear the system clipboard")
+	}
+
+	return true, nil
+}
@@ -0,0 +1,83 @@
+package bore_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.trulyao.dev/bore/v2"
+	"go.trulyao.dev/bore/v2/pkg/clipboard"
+	"go.trulyao.dev/bore/v2/pkg/errs"
+)
+
+// memoryClipboard is a system clipboard kept in memory.
+type memoryClipboard struct {
+	content     []byte
+	unavailable bool
+}
+
+func (m *memoryClipboard) Available() bool { return !m.unavailable }
+
+func (m *memoryClipboard) Write(_ context.Context, data []byte) error {
+	m.content = append([]byte(nil), data...)
+	return nil
+}
+
+func (m *memoryClipboard) Read(context.Context) ([]byte, error) { return m.content, nil }
+
+func (m *memoryClipboard) Clear(context.Context) error {
+	m.content = nil
+	return nil
+}
+
+func (m *memoryClipboard) Binaries() clipboard.Binaries { return clipboard.Binaries{} }
+
+var _ clipboard.NativeClipboard = (*memoryClipboard)(nil)
+
+func Test_ClearEphemeral(t *testing.T) {
+	ctx := context.Background()
+	b := newBore(t)
+	system := &memoryClipboard{}
+	b.SetClipboard(system)
+
+	checksum, err := b.Clipboard().CopyEphemeral(ctx, []byte("one-time code"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if string(system.content) != "one-time code" {
+		t.Fatalf("expected the content to be on the system clipboard, got %q", system.content)
+	}
+
+	if latest, err := b.Get(ctx, bore.GetClipboardOptions{}); err != nil || latest.Item != nil {
+		t.Errorf("expected nothing to be recorded in the history, got %+v (%v)", latest.Item, err)
+	}
+
+	// Anything copied since is left alone.
+	system.content = []byte("copied since")
+	if cleared, err := b.Clipboard().ClearEphemeral(ctx, checksum); err != nil || cleared {
+		t.Fatalf("expected the clipboard to be left alone, got %v (%v)", cleared, err)
+	}
+
+	if string(system.content) != "copied since" {
+		t.Errorf("expected the newer content to be kept, got %q", system.content)
+	}
+
+	system.content = []byte("one-time code")
+	if cleared, err := b.Clipboard().ClearEphemeral(ctx, checksum); err != nil || !cleared {
+		t.Fatalf("expected the clipboard to be cleared, got %v (%v)", cleared, err)
+	}
+
+	if len(system.content) != 0 {
+		t.Errorf("expected the clipboard to be empty, got %q", system.content)
+	}
+}
+
+func Test_ClearEphemeralUnavailable(t *testing.T) {
+	b := newBore(t)
+	b.SetClipboard(&memoryClipboard{unavailable: true})
+
+	if _, err := b.Clipboard().ClearEphemeral(context.Background(), "checksum"); !errors.Is(err, errs.ErrClipboardUnavailable) {
+		t.Errorf("expected ErrClipboardUnavailable, got %v", err)
+	}
+}
@@ -0,0 +1,8 @@
+package bore
+
+import "go.trulyao.dev/bore/v2/pkg/clipboard"
+
+// SetClipboard replaces the system clipboard, so tests do not depend on the clipboard tools of the platform.
+func (b *Bore) SetClipboard(c clipboard.NativeClipboard) {
+	b.clipboard = c
+}
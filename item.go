@@ -2,8 +2,16 @@ package bore
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
 	"strings"
+	"time"
 
+	"go.trulyao.dev/bore/v2/database"
 	"go.trulyao.dev/bore/v2/database/models"
 	"go.trulyao.dev/bore/v2/database/repository"
 	"go.trulyao.dev/bore/v2/pkg/errs"
@@ -20,41 +28,212 @@ type clipboardNamespace struct {
 
 type (
 	SetClipboardOptions struct {
-		Passthrough  bool   // Whether to also copy to the system clipboard if available.
-		CollectionID string // Optional collection ID to associate with the copied item.
-		Mimetype     mimetype.MimeType
+		Passthrough  bool              // Whether to also copy to the system clipboard if available.
+		CollectionID string            // Optional collection name or ID to associate with the copied item.
+		Mimetype     mimetype.MimeType // Optional mimetype, detected from the content (and Filename) if empty.
+		Filename     string            // Optional name of the file the data was read from, its extension helps detect the mimetype.
+		ExpiresAt    time.Time         // Optional time after which the item is hidden and deleted.
+		MaxPastes    int               // Optional number of pastes after which the item is deleted ("burn after reading").
+
+		// Sensitive masks the item in listings and keeps it out of the search index, it is also set by the mark-sensitive secret policy.
+		// Sensitive items expire after Config.SensitiveTTLPeriod unless ExpiresAt is set, and copying existing content as sensitive marks that item as sensitive.
+		Sensitive bool
+
+		// Append extends the latest item in the collection instead of creating a new one, a new item is only created if the collection is empty.
+		// ExpiresAt, MaxPastes and Sensitive are ignored when an item is extended.
+		Append    bool
+		Separator string // Inserted between the existing content and the appended data.
 	}
 
 	GetClipboardOptions struct {
-		ItemID              string // Optional item identifier to filter pasted items.
-		CollectionID        string // Optional collection ID to filter pasted items.
+		ItemID              string // Optional item identifier or relative reference (`@N`, `collection@N`) to filter pasted items.
+		CollectionID        string // Optional collection name or ID to filter pasted items.
 		FromSystemClipboard bool   // Whether to paste from the system clipboard if available.
 		DeleteAfterPaste    bool   // Whether to delete the pasted item after pasting.
+		ForceDelete         bool   // Whether to delete the pasted item even if it is pinned, pinned items are kept otherwise.
+		PurgeAfterPaste     bool   // Whether to purge the pasted item instead of deleting it, see clipboardNamespace.Purge.
 		SkipCollectionCheck bool   // Whether to skip checking if the collection exists.
 	}
 
 	PasteResult struct {
 		Content []byte
 		Item    *models.Item
+		Deleted bool // Whether the item no longer exists and its content was recovered from the event log.
+	}
+
+	ListClipboardOptions struct {
+		CollectionID  string    // Optional collection ID to filter items by, all collections if empty.
+		Mimetype      string    // Optional mimetype to filter items by.
+		CreatedAfter  time.Time // Optional lower bound (inclusive) for the creation time.
+		CreatedBefore time.Time // Optional upper bound (exclusive) for the creation time.
+		UpdatedAfter  time.Time // Optional lower bound (inclusive) for the last update time.
+		UpdatedBefore time.Time // Optional upper bound (exclusive) for the last update time.
+		PinnedFirst   bool      // Whether to list pinned items before all others.
+		Cursor        int64     // Opaque cursor returned by a previous page, zero to start from the first item.
+		Limit         int       // Maximum number of items to return, zero for no limit.
+	}
+
+	ListClipboardResult struct {
+		Items      models.Items
+		NextCursor int64 // Cursor for the next page, zero if there are no more items.
+	}
+
+	DeleteItemOptions struct {
+		Force bool // Whether to delete the item even if it is pinned.
+		Purge bool // Whether to purge the item instead of moving it to the trash, see clipboardNamespace.Purge.
+	}
+
+	FindItemResult struct {
+		Item    *models.Item
+		Version int64 // The version of the item's event stream, to pass as UpdateItemOptions.ExpectedVersion.
+	}
+
+	UpdateItemOptions struct {
+		// ExpectedVersion is the version the item must still be at, see events.AppendOptions. Negative to skip the check.
+		ExpectedVersion int64
+	}
+
+	UpdateResult struct {
+		ItemID string // The ID of the item that now holds the content.
+		Merged bool   // Whether the item was merged into an existing item with the same content.
+	}
+
+	SetResult struct {
+		Secret *SecretMatch // Set if the data looks like a secret, but the collection's policy still allowed the copy.
+	}
+
+	// SecretMatch describes copied content that matched a secret detection rule.
+	SecretMatch struct {
+		Rule      string
+		Sensitive bool // Whether the copied item was marked as sensitive because of the match.
+	}
+
+	MoveResult struct {
+		ItemID string // The ID of the item in the target collection.
+		Merged bool   // Whether the item was merged into an existing item with the same content.
 	}
 )
 
 // Set copies the provided data to the Bore instance.
-func (i *clipboardNamespace) Set(ctx context.Context, data []byte, opts SetClipboardOptions) error {
-	forwardToSystemClipboard := i.config.ClipboardPassthrough || opts.Passthrough
-	if i.clipboard.Available() && forwardToSystemClipboard {
-		if err := i.clipboard.Write(ctx, data); err != nil {
-			return err
+// If the data matches a secret detection rule and the collection's policy refuses it, a *errs.SecretDetectedError is returned, other matches are reported in the result.
+func (i *clipboardNamespace) Set(ctx context.Context, data []byte, opts SetClipboardOptions) (SetResult, error) {
+	collectionName, err := i.resolveCollection(ctx, &opts)
+	if err != nil {
+		return SetResult{}, err
+	}
+
+	secret, err := i.detectSecret(data, opts.CollectionID, collectionName)
+	if err != nil {
+		return SetResult{}, err
+	}
+
+	if secret != nil {
+		opts.Sensitive = opts.Sensitive || secret.Sensitive
+	}
+
+	if opts.Append {
+		latest, err := i.repository.Items().FindLatest(ctx, opts.CollectionID)
+		if err != nil {
+			return SetResult{}, errs.New("failed to find latest item").WithError(err)
+		}
+
+		if latest != nil {
+			if err := i.appendTo(ctx, latest, data, opts); err != nil {
+				return SetResult{}, err
+			}
+
+			// Appended content is never marked as sensitive.
+			if secret != nil {
+				secret.Sensitive = false
+			}
+			return SetResult{Secret: secret}, nil
 		}
 	}
 
-	hash := lib.ComputeChecksum(data)
-	existingItem, err := i.repository.Items().FindByHash(ctx, hash, opts.CollectionID)
+	if err := i.create(ctx, itemContent{data: data, blobHash: "", size: 0}, opts); err != nil {
+		return SetResult{}, err
+	}
+
+	return SetResult{Secret: secret}, nil
+}
+
+// resolveCollection replaces the collection name or ID in the options with the ID of the collection, and returns its name.
+// The collection is resolved up front, an unknown collection would otherwise only fail on the foreign key in the projection.
+func (i *clipboardNamespace) resolveCollection(ctx context.Context, opts *SetClipboardOptions) (string, error) {
+	if opts.CollectionID = strings.TrimSpace(opts.CollectionID); opts.CollectionID == "" {
+		return "", nil
+	}
+
+	collection, err := i.Collections().Get(ctx, opts.CollectionID)
+	if err != nil {
+		return "", err
+	}
+
+	opts.CollectionID = collection.ID
+	return collection.Name, nil
+}
+
+// detectSecret checks the data against the secret detection rules and applies the collection's policy to a match.
+// A refusal is returned as a *errs.SecretDetectedError, otherwise the match (if any) is returned.
+func (i *clipboardNamespace) detectSecret(data []byte, collectionID, collectionName string) (*SecretMatch, error) {
+	policy := i.config.SecretDetection.PolicyFor(collectionID, collectionName)
+	if policy == SecretPolicyOff {
+		return nil, nil
+	}
+
+	rule, ok := i.detector.Detect(data)
+	if !ok {
+		return nil, nil
+	}
+
+	if policy == SecretPolicyRefuse {
+		return nil, &errs.SecretDetectedError{Rule: rule}
+	}
+
+	return &SecretMatch{Rule: rule, Sensitive: policy == SecretPolicyMarkSensitive}, nil
+}
+
+// String describes the match, as shown in warnings.
+func (m *SecretMatch) String() string {
+	message := fmt.Sprintf("content looks like a secret (matched rule %q)", m.Rule)
+	if m.Sensitive {
+		return message + ", it was marked as sensitive"
+	}
+
+	return message
+}
+
+// create copies the content as a new item, or moves the item with the same content to the top of the history.
+func (i *clipboardNamespace) create(ctx context.Context, content itemContent, opts SetClipboardOptions) error {
+	if opts.Mimetype == "" {
+		opts.Mimetype = mimetype.Detect(content.data, opts.Filename)
+	}
+
+	if err := i.forwardToSystemClipboard(ctx, content, opts); err != nil {
+		return err
+	}
+
+	existingItem, err := i.repository.Items().FindByHash(ctx, content.hash(), opts.CollectionID)
 	if err != nil {
 		return errs.New("failed to check for existing item").WithError(err)
 	}
 
-	var e *events.Event
+	// An expired item that has not been swept yet must not be revived by a bump, it is replaced with a fresh one instead.
+	if existingItem != nil && existingItem.IsExpired(time.Now()) {
+		if err := i.deleteItem(ctx, existingItem.ID); err != nil {
+			return err
+		}
+		existingItem = nil
+	}
+
+	// Copying content as sensitive, whether new or not, starts the default expiry unless one was given.
+	if opts.Sensitive && opts.ExpiresAt.IsZero() {
+		if ttl := i.config.SensitiveTTLPeriod(); ttl > 0 {
+			opts.ExpiresAt = time.Now().Add(ttl)
+		}
+	}
+
+	// Bumping an existing item only changes the order of the history, so only new items can be undone.
 	if existingItem != nil {
 		var existingAgg aggregate.Aggregate
 		existingAgg, err = aggregate.WithID(aggregate.AggregateTypeItem, existingItem.ID)
@@ -62,91 +241,798 @@ func (i *clipboardNamespace) Set(ctx context.Context, data []byte, opts SetClipb
 			return errs.New("failed to create aggregate for existing item").WithError(err)
 		}
 
-		e, err = events.New(existingAgg, &payload.BumpItem{})
+		e, err := events.New(existingAgg, &payload.BumpItem{
+			ExpiresAt: opts.ExpiresAt.UTC(),
+			MaxPastes: opts.MaxPastes,
+			Sensitive: opts.Sensitive && !existingItem.Sensitive,
+		})
+		if err != nil {
+			return errs.New("failed to create copy event: ").WithError(err)
+		}
+
+		if _, _, err = i.manager.Apply(ctx, e, events.AppendOptions{ExpectedVersion: -1}); err != nil {
+			return errs.New("failed to apply copy event").WithError(err)
+		}
 	} else {
-		e, err = events.NewWithGeneratedID(
+		e, err := events.NewWithGeneratedID(
 			aggregate.AggregateTypeItem,
 			&payload.CreateItem{
-				Content:      data,
+				ContentHash:  content.hash(),
+				Content:      nil,
 				Mimetype:     opts.Mimetype,
 				CollectionID: opts.CollectionID,
+				ExpiresAt:    opts.ExpiresAt.UTC(),
+				MaxPastes:    opts.MaxPastes,
+				Sensitive:    opts.Sensitive,
 			},
 		)
+		if err != nil {
+			return errs.New("failed to create copy event: ").WithError(err)
+		}
+		e.Content = content.stored()
+
+		undo, err := newEvent(aggregate.AggregateTypeItem, e.AggregateID, &payload.DeleteItem{})
+		if err != nil {
+			return err
+		}
+
+		err = i.applyUndoable(ctx, "copy item "+e.AggregateID, []*events.Event{e}, []*events.Event{undo})
+		if err != nil {
+			return errs.New("failed to apply copy event").WithError(err)
+		}
+	}
+
+	i.pruneAfterCopy(ctx, opts.CollectionID)
+
+	return nil
+}
+
+// appendTo extends the item with the data, moving it to the top of the history.
+func (i *clipboardNamespace) appendTo(
+	ctx context.Context,
+	item *models.Item,
+	data []byte,
+	opts SetClipboardOptions,
+) error {
+	if item.InBlobStore() {
+		return errs.ErrItemInBlobStore
+	}
+
+	p := &payload.AppendItemContent{Content: data, Separator: opts.Separator}
+	content := append(slices.Clip(item.Content), p.Suffix()...)
+
+	forwardToSystemClipboard := i.config.ClipboardPassthrough || opts.Passthrough
+	if i.clipboard.Available() && forwardToSystemClipboard {
+		if err := i.clipboard.Write(ctx, content); err != nil {
+			return err
+		}
+	}
+
+	e, err := newEvent(aggregate.AggregateTypeItem, item.ID, p)
+	if err != nil {
+		return err
+	}
+
+	// Appending can produce content that already exists, in which case the item is merged away and undoing has to recreate it.
+	duplicate, err := i.repository.Items().FindByHash(ctx, lib.ComputeChecksum(content), item.CollectionID.String)
+	if err != nil {
+		return errs.New("failed to check for duplicate item").WithError(err)
+	}
+
+	var undo []*events.Event
+	if duplicate != nil && duplicate.ID != item.ID &&
+		duplicate.CollectionID.String == item.CollectionID.String {
+		undo, err = recreateItemEvents(item)
+	} else {
+		var revert *events.Event
+		revert, err = newEvent(aggregate.AggregateTypeItem, item.ID, &payload.UpdateItemContent{
+			ContentHash: item.Hash,
+			Content:     nil,
+		})
+		undo = []*events.Event{revert}
 	}
 	if err != nil {
-		return errs.New("failed to create copy event: ").WithError(err)
+		return err
 	}
 
-	if _, _, err = i.manager.Apply(ctx, e, events.AppendOptions{ExpectedVersion: -1}); err != nil {
-		return errs.New("failed to apply copy event").WithError(err)
+	if err := i.applyUndoable(ctx, "append to item "+item.ID, []*events.Event{e}, undo); err != nil {
+		return errs.New("failed to apply append event").WithError(err)
 	}
 
+	i.pruneAfterCopy(ctx, opts.CollectionID)
+
 	return nil
 }
 
-// Get retrieves the last copied data from the Bore instance.
+// Get retrieves the last copied data from the Bore instance, content kept in the blob store is read into memory.
 func (b *Bore) Get(ctx context.Context, options GetClipboardOptions) (PasteResult, error) {
+	return b.get(ctx, options, nil)
+}
+
+// GetTo is Get for content that may be too large to hold in memory, the content is streamed to w and left out of the result.
+// It is written before the item is deleted by DeleteAfterPaste, so nothing is deleted if writing fails.
+func (b *Bore) GetTo(ctx context.Context, w io.Writer, options GetClipboardOptions) (PasteResult, error) {
+	return b.get(ctx, options, w)
+}
+
+// get retrieves the item to paste, its content is written to w if set and returned otherwise.
+func (b *Bore) get(ctx context.Context, options GetClipboardOptions, w io.Writer) (PasteResult, error) {
 	if b.clipboard.Available() && options.FromSystemClipboard {
 		rawContent, err := b.clipboard.Read(ctx)
 		if err != nil {
 			return PasteResult{}, errs.New("failed to read from system clipboard").WithError(err)
 		}
 
+		if w != nil {
+			_, err = w.Write(rawContent)
+			return PasteResult{Content: nil, Item: nil}, err
+		}
+
 		return PasteResult{Content: rawContent, Item: nil}, nil
 	}
 
 	options.CollectionID = strings.TrimSpace(options.CollectionID)
 	if options.CollectionID != "" && !options.SkipCollectionCheck {
-		existingCollection, err := b.repository.Collections().
-			FindOne(ctx, repository.CollectionLookupOptions{
-				Identifier: options.CollectionID,
-				Name:       "",
-			})
+		existingCollection, err := b.Collections().Get(ctx, options.CollectionID)
+		if err != nil {
+			return PasteResult{}, err
+		}
+
+		options.CollectionID = existingCollection.ID
+	}
+
+	// Expired items are already hidden from lookups, this makes sure they are actually removed too.
+	if _, err := b.Clipboard().sweepExpired(ctx, false); err != nil {
+		slog.Warn("failed to delete expired items", slog.String("error", err.Error()))
+	}
+
+	item, err := b.findItem(ctx, options.ItemID, options.CollectionID)
+	if err != nil {
+		return PasteResult{}, errs.New("failed to find latest item").WithError(err)
+	}
+
+	if item == nil {
+		identifier := strings.TrimSpace(options.ItemID)
+		if identifier == "" || IsItemReference(identifier) {
+			// nolint: exhaustruct
+			return PasteResult{}, nil
+		}
+
+		result, err := b.Clipboard().recoverDeleted(ctx, identifier, options.CollectionID)
+		if err != nil || result.Item == nil {
+			return result, err
+		}
+
+		result.Content, err = b.Clipboard().deliver(result.Item, w)
+		return result, err
+	}
+
+	content, err := b.Clipboard().deliver(item, w)
+	if err != nil {
+		return PasteResult{}, err
+	}
+
+	deleted := false
+	if item.MaxPastes > 0 {
+		if deleted, err = b.Clipboard().recordPaste(ctx, item); err != nil {
+			return PasteResult{}, err
+		}
+	}
+
+	// Pinned items survive a paste-and-delete unless the caller insists.
+	if options.DeleteAfterPaste && (!item.IsPinned() || options.ForceDelete) {
+		switch {
+		case !deleted:
+			deleteOptions := DeleteItemOptions{Force: true, Purge: options.PurgeAfterPaste}
+			err = b.Clipboard().Delete(ctx, item.ID, deleteOptions)
+		case options.PurgeAfterPaste:
+			// The last allowed paste already moved the item to the trash, so it is purged from there.
+			err = b.Clipboard().purge(ctx, item.ID)
+		}
 		if err != nil {
-			return PasteResult{}, errs.New("failed to check if collection exists").WithError(err)
-		} else if existingCollection == nil {
-			return PasteResult{}, errs.ErrCollectionNotFound
+			return PasteResult{}, err
 		}
 	}
 
+	return PasteResult{Content: content, Item: item}, nil
+}
+
+// Delete removes the item with the given ID from the clipboard history.
+// Pinned items are only deleted if forced.
+func (i *clipboardNamespace) Delete(
+	ctx context.Context,
+	itemID string,
+	options DeleteItemOptions,
+) error {
+	item, err := i.repository.Items().FindById(ctx, itemID, "")
+	if err != nil {
+		return errs.New("failed to find item").WithError(err)
+	}
+
+	if item == nil {
+		return errs.ErrItemNotFound
+	}
+
+	if item.IsPinned() && !options.Force {
+		return errs.ErrItemPinned
+	}
+
+	if options.Purge {
+		return i.purge(ctx, item.ID)
+	}
+
+	// The pin is checked again when the event is applied, in case the item was pinned in the meantime.
+	e, err := newEvent(aggregate.AggregateTypeItem, item.ID, &payload.DeleteItem{KeepPinned: !options.Force})
+	if err != nil {
+		return err
+	}
+
+	undo, err := newEvent(aggregate.AggregateTypeItem, item.ID, &payload.RestoreItem{})
+	if err != nil {
+		return err
+	}
+
+	err = i.applyUndoable(ctx, "delete item "+item.ID, []*events.Event{e}, []*events.Event{undo})
+	if errors.Is(err, errs.ErrItemPinned) {
+		return errs.ErrItemPinned
+	} else if err != nil {
+		return errs.New("failed to apply delete event").WithError(err)
+	}
+
+	return nil
+}
+
+// Purge permanently erases an item, deleted items included, so its content can not be recovered from the trash, the undo history or the event log.
+// The item's event stream is kept with the content redacted, and the database is compacted afterwards so the content does not linger in free pages either.
+// This can not be undone, and pinned items are only purged if forced.
+func (i *clipboardNamespace) Purge(
+	ctx context.Context,
+	identifier string,
+	collection string,
+	options DeleteItemOptions,
+) (string, error) {
+	itemID, item, err := i.resolveStream(ctx, identifier, collection)
+	if err != nil {
+		return "", err
+	}
+
+	if item != nil && item.IsPinned() && !options.Force {
+		return "", errs.ErrItemPinned
+	}
+
+	return itemID, i.purge(ctx, itemID)
+}
+
+func (i *clipboardNamespace) purge(ctx context.Context, itemID string) error {
+	timeline, err := i.manager.ItemTimeline(ctx, itemID)
+	if err != nil {
+		return errs.New("failed to read item events").WithError(err)
+	}
+
+	if err := i.manager.PurgeItem(ctx, itemID); err != nil {
+		return errs.New("failed to purge item").WithError(err)
+	}
+
+	if err := database.Compact(ctx, i.db); err != nil {
+		return errs.New("item was purged, but the database could not be compacted").WithError(err)
+	}
+
+	// Another item may still use the same blob, in which case it is kept.
+	if timeline != nil && timeline.BlobHash != "" {
+		if _, err := i.collectContents(ctx, time.Now().Add(-blobGracePeriod), timeline.BlobHash); err != nil {
+			return errs.New("item was purged, but its content could not be removed from the blob store").WithError(err)
+		}
+	}
+
+	return nil
+}
+
+// deleteItem applies a delete event for the item without any of the checks done by Delete.
+func (i *clipboardNamespace) deleteItem(ctx context.Context, itemID string) error {
+	agg, err := aggregate.WithID(aggregate.AggregateTypeItem, itemID)
+	if err != nil {
+		return errs.New("failed to create aggregate for deletion event").WithError(err)
+	}
+
+	e, err := events.New(agg, &payload.DeleteItem{})
+	if err != nil {
+		return errs.New("failed to create delete event").WithError(err)
+	}
+
+	if _, _, err = i.manager.Apply(ctx, e); err != nil {
+		return errs.New("failed to apply delete event").WithError(err)
+	}
+
+	return nil
+}
+
+// recordPaste records a paste of an item with a paste limit, and deletes it once the limit is reached.
+// It reports whether the item was deleted.
+func (i *clipboardNamespace) recordPaste(ctx context.Context, item *models.Item) (bool, error) {
+	agg, err := aggregate.WithID(aggregate.AggregateTypeItem, item.ID)
+	if err != nil {
+		return false, errs.New("failed to create aggregate for paste event").WithError(err)
+	}
+
+	e, err := events.New(agg, &payload.PasteItem{})
+	if err != nil {
+		return false, errs.New("failed to create paste event").WithError(err)
+	}
+
+	if _, _, err = i.manager.Apply(ctx, e); err != nil {
+		return false, errs.New("failed to apply paste event").WithError(err)
+	}
+
+	if item.PasteCount+1 < item.MaxPastes || item.IsPinned() {
+		return false, nil
+	}
+
+	return true, i.deleteItem(ctx, item.ID)
+}
+
+// List returns clipboard items matching the provided filters, most recent first.
+// Pagination is keyset-based, pass the returned NextCursor to fetch the next page.
+func (i *clipboardNamespace) List(
+	ctx context.Context,
+	options ListClipboardOptions,
+) (ListClipboardResult, error) {
+	repoOptions := repository.FindItemsOptions{
+		CollectionID:     strings.TrimSpace(options.CollectionID),
+		Mimetype:         strings.TrimSpace(options.Mimetype),
+		CreatedAfter:     options.CreatedAfter,
+		CreatedBefore:    options.CreatedBefore,
+		UpdatedAfter:     options.UpdatedAfter,
+		UpdatedBefore:    options.UpdatedBefore,
+		PinnedFirst:      options.PinnedFirst,
+		BeforeSequenceID: options.Cursor,
+		BeforePinned:     false,
+		Limit:            0,
+	}
+
+	// A negative cursor points at a pinned item, see below.
+	if options.Cursor < 0 {
+		repoOptions.BeforeSequenceID = -options.Cursor
+		repoOptions.BeforePinned = true
+	}
+
+	// We fetch one extra item to know whether there is another page without a separate COUNT query.
+	if options.Limit > 0 {
+		repoOptions.Limit = options.Limit + 1
+	}
+
+	items, err := i.repository.Items().FindAll(ctx, repoOptions)
+	if err != nil {
+		return ListClipboardResult{}, errs.New("failed to list items").WithError(err)
+	}
+
+	result := ListClipboardResult{Items: items, NextCursor: 0}
+	if options.Limit > 0 && len(items) > options.Limit {
+		result.Items = items[:options.Limit]
+
+		last := result.Items[len(result.Items)-1]
+		result.NextCursor = last.LastAppliedSequenceID
+
+		// Pinned and unpinned items are paginated as two runs when listed pinned-first, so the cursor has to record which run it stopped in.
+		if options.PinnedFirst && last.IsPinned() {
+			result.NextCursor = -result.NextCursor
+		}
+	}
+
+	return result, nil
+}
+
+// Pin pins an item so it is listed first and protected from deletion and pruning.
+func (i *clipboardNamespace) Pin(ctx context.Context, itemID string) error {
+	return i.setPinned(ctx, itemID, true)
+}
+
+// Unpin removes the pin from an item.
+func (i *clipboardNamespace) Unpin(ctx context.Context, itemID string) error {
+	return i.setPinned(ctx, itemID, false)
+}
+
+func (i *clipboardNamespace) setPinned(ctx context.Context, itemID string, pinned bool) error {
+	item, err := i.repository.Items().FindById(ctx, itemID, "")
+	if err != nil {
+		return errs.New("failed to find item").WithError(err)
+	}
+
+	if item == nil {
+		return errs.ErrItemNotFound
+	}
+
+	// Nothing to record if the item is already in the requested state.
+	if item.IsPinned() == pinned {
+		return nil
+	}
+
 	var (
-		item *models.Item
-		err  error
+		p, inverse  payload.Payload = &payload.PinItem{}, &payload.UnpinItem{}
+		description                 = "pin item " + item.ID
 	)
+	if !pinned {
+		p, inverse = inverse, p
+		description = "unpin item " + item.ID
+	}
 
-	identifier := strings.TrimSpace(options.ItemID)
-	if identifier == "" {
-		item, err = b.repository.Items().FindLatest(ctx, options.CollectionID)
-	} else {
-		item, err = b.repository.Items().FindById(ctx, identifier, options.CollectionID)
+	e, err := newEvent(aggregate.AggregateTypeItem, item.ID, p)
+	if err != nil {
+		return err
 	}
 
+	undo, err := newEvent(aggregate.AggregateTypeItem, item.ID, inverse)
 	if err != nil {
-		return PasteResult{}, errs.New("failed to find latest item").WithError(err)
+		return err
+	}
+
+	if err := i.applyUndoable(ctx, description, []*events.Event{e}, []*events.Event{undo}); err != nil {
+		return errs.New("failed to apply pin event").WithError(err)
+	}
+
+	return nil
+}
+
+// Find returns an item without recording a paste, along with the current version of its event stream.
+// The identifier can be an ID, a unique ID prefix or a relative reference, the latest item in the collection is returned if it is empty.
+func (i *clipboardNamespace) Find(
+	ctx context.Context,
+	identifier string,
+	collection string,
+) (FindItemResult, error) {
+	collectionID := ""
+	if collection = strings.TrimSpace(collection); collection != "" {
+		target, err := i.Collections().Get(ctx, collection)
+		if err != nil {
+			return FindItemResult{}, err
+		}
+
+		collectionID = target.ID
+	}
+
+	item, err := i.findItem(ctx, identifier, collectionID)
+	if err != nil {
+		return FindItemResult{}, errs.New("failed to find item").WithError(err)
+	}
+
+	if item == nil {
+		return FindItemResult{}, errs.ErrItemNotFound
+	}
+
+	agg, err := aggregate.WithID(aggregate.AggregateTypeItem, item.ID)
+	if err != nil {
+		return FindItemResult{}, errs.New("failed to create aggregate for item").WithError(err)
+	}
+
+	version, err := i.manager.Version(ctx, agg)
+	if err != nil {
+		return FindItemResult{}, errs.New("failed to read item version").WithError(err)
+	}
+
+	return FindItemResult{Item: item, Version: version}, nil
+}
+
+// Update replaces the content of an item.
+// If the item's collection already contains the new content, the item is merged into the existing one.
+// ErrItemModified is returned if the item is no longer at options.ExpectedVersion.
+func (i *clipboardNamespace) Update(
+	ctx context.Context,
+	itemID string,
+	content []byte,
+	options UpdateItemOptions,
+) (UpdateResult, error) {
+	item, err := i.repository.Items().FindById(ctx, itemID, "")
+	if err != nil {
+		return UpdateResult{}, errs.New("failed to find item").WithError(err)
 	}
 
 	if item == nil {
+		return UpdateResult{}, errs.ErrItemNotFound
+	}
+
+	if item.InBlobStore() {
+		return UpdateResult{}, errs.ErrItemInBlobStore
+	}
+
+	hash := lib.ComputeChecksum(content)
+	if hash == item.Hash {
+		return UpdateResult{ItemID: item.ID, Merged: false}, nil
+	}
+
+	e, err := newEvent(aggregate.AggregateTypeItem, item.ID, &payload.UpdateItemContent{ContentHash: hash, Content: nil})
+	if err != nil {
+		return UpdateResult{}, err
+	}
+	e.Content = itemContent{data: content, blobHash: "", size: 0}.stored()
+
+	// An update that merges the item into a duplicate deletes it, so undoing it has to recreate the item.
+	duplicate, err := i.repository.Items().FindByHash(ctx, hash, item.CollectionID.String)
+	if err != nil {
+		return UpdateResult{}, errs.New("failed to check for duplicate item").WithError(err)
+	}
+
+	merged := duplicate != nil && duplicate.CollectionID.String == item.CollectionID.String
+
+	var undo []*events.Event
+	if merged {
+		undo, err = recreateItemEvents(item)
+	} else {
+		var revert *events.Event
+		revert, err = newEvent(aggregate.AggregateTypeItem, item.ID, &payload.UpdateItemContent{
+			ContentHash: item.Hash,
+			Content:     nil,
+		})
+		undo = []*events.Event{revert}
+	}
+	if err != nil {
+		return UpdateResult{}, err
+	}
+
+	var expectedVersions map[string]int64
+	if options.ExpectedVersion >= 0 {
+		expectedVersions = map[string]int64{item.ID: options.ExpectedVersion}
+	}
+
+	description := "edit item " + item.ID
+	err = i.applyUndoableExpecting(ctx, description, []*events.Event{e}, undo, expectedVersions)
+	if errors.Is(err, events.ErrVersionMismatch) {
+		return UpdateResult{}, errs.ErrItemModified
+	} else if err != nil {
+		return UpdateResult{}, errs.New("failed to apply update event").WithError(err)
+	}
+
+	if merged {
+		return UpdateResult{ItemID: duplicate.ID, Merged: true}, nil
+	}
+
+	return UpdateResult{ItemID: item.ID, Merged: false}, nil
+}
+
+// Timeline returns the history of an item from its event stream, deleted items included.
+// The identifier can be an ID, a unique ID prefix or a relative reference, the latest item in the collection is used if it is empty.
+func (i *clipboardNamespace) Timeline(
+	ctx context.Context,
+	identifier string,
+	collection string,
+) (*events.ItemTimeline, error) {
+	itemID, item, err := i.resolveStream(ctx, identifier, collection)
+	if err != nil {
+		return nil, err
+	}
+
+	timeline, err := i.manager.ItemTimeline(ctx, itemID)
+	if err != nil {
+		return nil, errs.New("failed to read item events").WithError(err)
+	}
+
+	if timeline == nil {
+		return nil, errs.ErrItemNotFound
+	}
+
+	// Deleting a collection or merging into a duplicate removes items without an event in their own stream.
+	if item == nil && !timeline.Deleted {
+		existing, err := i.repository.Items().FindById(ctx, itemID, "")
+		if err != nil {
+			return nil, errs.New("failed to find item").WithError(err)
+		}
+
+		timeline.Deleted = existing == nil
+	}
+
+	return timeline, nil
+}
+
+// resolveStream resolves the identifier to the ID of an item's event stream, falling back to deleted items if no current item matches.
+// The current item is returned as well if there is one.
+func (i *clipboardNamespace) resolveStream(
+	ctx context.Context,
+	identifier string,
+	collection string,
+) (string, *models.Item, error) {
+	found, err := i.Find(ctx, identifier, collection)
+	if err != nil && !errors.Is(err, errs.ErrItemNotFound) {
+		return "", nil, err
+	}
+
+	if found.Item != nil {
+		return found.Item.ID, found.Item, nil
+	}
+
+	var itemID string
+	if identifier = strings.TrimSpace(identifier); identifier != "" && !IsItemReference(identifier) {
+		if itemID, err = i.manager.ResolveItemID(ctx, identifier); err != nil {
+			return "", nil, errs.Wrap(err, "failed to resolve item id")
+		}
+	}
+
+	if itemID == "" {
+		return "", nil, errs.ErrItemNotFound
+	}
+
+	return itemID, nil, nil
+}
+
+// recoverDeleted returns the last state of a deleted item from its event stream, its content is left for the caller to deliver.
+// Items created with an expiry or a paste limit are never recovered, since that would defeat the point of the limit, and neither are purged items.
+func (i *clipboardNamespace) recoverDeleted(
+	ctx context.Context,
+	identifier string,
+	collectionID string,
+) (PasteResult, error) {
+	itemID, err := i.manager.ResolveItemID(ctx, identifier)
+	if err != nil {
+		return PasteResult{}, errs.Wrap(err, "failed to resolve item id")
+	}
+
+	if itemID == "" {
+		// nolint: exhaustruct
+		return PasteResult{}, nil
+	}
+
+	// The item may still exist outside the requested collection.
+	existing, err := i.repository.Items().FindById(ctx, itemID, "")
+	if err != nil {
+		return PasteResult{}, errs.New("failed to find item").WithError(err)
+	}
+
+	if existing != nil {
+		// nolint: exhaustruct
+		return PasteResult{}, nil
+	}
+
+	timeline, err := i.manager.ItemTimeline(ctx, itemID)
+	if err != nil {
+		return PasteResult{}, errs.New("failed to read item events").WithError(err)
+	}
+
+	if timeline == nil || timeline.Ephemeral || timeline.Purged ||
+		(collectionID != "" && timeline.CollectionID != collectionID) {
 		// nolint: exhaustruct
 		return PasteResult{}, nil
 	}
 
-	if options.DeleteAfterPaste {
-		agg, err := aggregate.WithID(aggregate.AggregateTypeItem, item.ID)
+	//nolint:exhaustruct
+	item := &models.Item{
+		ID:           timeline.ItemID,
+		Content:      timeline.Content,
+		Hash:         lib.ComputeChecksum(timeline.Content),
+		Mimetype:     timeline.Mimetype.String(),
+		CreatedAt:    timeline.CreatedAt,
+		BlobHash:     timeline.BlobHash,
+		ContentSize:  timeline.Size,
+		CollectionID: sql.NullString{String: timeline.CollectionID, Valid: timeline.CollectionID != ""},
+	}
+
+	if item.InBlobStore() {
+		item.Hash = item.BlobHash
+	}
+
+	return PasteResult{Content: nil, Item: item, Deleted: true}, nil
+}
+
+// Move moves an item to the collection with the given ID or name, or makes it uncategorized if collection is empty.
+// If the target collection already contains the same content, the item is merged into the existing one.
+func (i *clipboardNamespace) Move(
+	ctx context.Context,
+	itemID string,
+	collection string,
+) (MoveResult, error) {
+	item, err := i.repository.Items().FindById(ctx, itemID, "")
+	if err != nil {
+		return MoveResult{}, errs.New("failed to find item").WithError(err)
+	}
+
+	if item == nil {
+		return MoveResult{}, errs.ErrItemNotFound
+	}
+
+	collectionID, collectionName := "", "uncategorized"
+	if collection = strings.TrimSpace(collection); collection != "" {
+		target, err := i.Collections().Get(ctx, collection)
 		if err != nil {
-			return PasteResult{}, errs.New(
-				"failed to create aggregate for deletion event",
-			).WithError(err)
+			return MoveResult{}, err
 		}
 
-		e, err := events.New(agg, &payload.DeleteItem{})
+		collectionID, collectionName = target.ID, target.Name
+	}
+
+	if item.CollectionID.String == collectionID {
+		return MoveResult{ItemID: item.ID, Merged: false}, nil
+	}
+
+	e, err := newEvent(aggregate.AggregateTypeItem, item.ID, &payload.MoveItem{CollectionID: collectionID})
+	if err != nil {
+		return MoveResult{}, err
+	}
+
+	// A move into a collection that already has the content deletes the moved item, so undoing it has to recreate the item.
+	duplicate, err := i.repository.Items().FindByHash(ctx, item.Hash, collectionID)
+	if err != nil {
+		return MoveResult{}, errs.New("failed to check for duplicate item").WithError(err)
+	}
+
+	var undo []*events.Event
+	if duplicate != nil && duplicate.CollectionID.String == collectionID {
+		undo, err = recreateItemEvents(item)
+	} else {
+		var back *events.Event
+		back, err = newEvent(aggregate.AggregateTypeItem, item.ID, &payload.MoveItem{
+			CollectionID: item.CollectionID.String,
+		})
+		undo = []*events.Event{back}
+	}
+	if err != nil {
+		return MoveResult{}, err
+	}
+
+	description := "move item " + item.ID + " to " + collectionName
+	if err := i.applyUndoable(ctx, description, []*events.Event{e}, undo); err != nil {
+		return MoveResult{}, errs.New("failed to apply move event").WithError(err)
+	}
+
+	// The projection decides whether to merge, so look at where the content ended up.
+	moved, err := i.repository.Items().FindById(ctx, item.ID, "")
+	if err != nil {
+		return MoveResult{}, errs.New("failed to find moved item").WithError(err)
+	}
+
+	if moved != nil {
+		return MoveResult{ItemID: moved.ID, Merged: false}, nil
+	}
+
+	merged, err := i.repository.Items().FindByHash(ctx, item.Hash, collectionID)
+	if err != nil || merged == nil {
+		return MoveResult{}, errs.New("failed to find merged item").WithError(err)
+	}
+
+	return MoveResult{ItemID: merged.ID, Merged: true}, nil
+}
+
+// findItem returns the item with the given ID, ID prefix or relative reference, or the latest item if the identifier is empty.
+func (b *Bore) findItem(
+	ctx context.Context,
+	identifier string,
+	collectionID string,
+) (*models.Item, error) {
+	identifier = strings.TrimSpace(identifier)
+	switch {
+	case identifier == "":
+		return b.repository.Items().FindLatest(ctx, collectionID)
+	case IsItemReference(identifier):
+		return b.findReferencedItem(ctx, identifier, collectionID)
+	default:
+		return b.repository.Items().FindById(ctx, identifier, collectionID)
+	}
+}
+
+// findReferencedItem resolves a relative item reference, falling back to the given collection if the reference does not name one.
+func (b *Bore) findReferencedItem(
+	ctx context.Context,
+	identifier string,
+	collectionID string,
+) (*models.Item, error) {
+	reference, err := ParseItemReference(identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	if reference.Collection != "" {
+		collection, err := b.Collections().Get(ctx, reference.Collection)
 		if err != nil {
-			return PasteResult{}, errs.New("failed to create delete event").WithError(err)
+			return nil, err
 		}
 
-		if _, _, err = b.manager.Apply(ctx, e); err != nil {
-			return PasteResult{}, errs.New("failed to apply delete event").WithError(err)
-		}
+		collectionID = collection.ID
+	}
+
+	return b.repository.Items().FindNthLatest(ctx, collectionID, reference.Offset)
+}
+
+// ShortIDs returns the shortest unique prefix of each of the given item IDs, for display.
+func (i *clipboardNamespace) ShortIDs(ctx context.Context, ids []string) (map[string]string, error) {
+	prefixes, err := i.repository.Items().ShortestPrefixes(ctx, ids)
+	if err != nil {
+		return nil, errs.New("failed to compute short item ids").WithError(err)
 	}
 
-	return PasteResult{Content: item.Content, Item: item}, nil
+	return prefixes, nil
 }
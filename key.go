@@ -0,0 +1,213 @@
+package bore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/uptrace/bun"
+	"go.trulyao.dev/bore/v2/database"
+	"go.trulyao.dev/bore/v2/database/models"
+	"go.trulyao.dev/bore/v2/database/repository"
+	"go.trulyao.dev/bore/v2/pkg/encryption"
+	"go.trulyao.dev/bore/v2/pkg/errs"
+	"go.trulyao.dev/bore/v2/pkg/events"
+)
+
+type keyNamespace struct {
+	*Bore
+}
+
+// KeySource is the key to encrypt the data with in Init and Rotate.
+type KeySource struct {
+	// KeyFile is the path to the key file, a new random key is written to it if it does not exist yet.
+	KeyFile string
+
+	// Passphrase is used to derive the key when KeyFile is empty.
+	Passphrase []byte
+}
+
+// Enabled reports whether the data is encrypted.
+func (k *keyNamespace) Enabled() bool {
+	return k.repository.Cipher().Enabled()
+}
+
+// Init encrypts the existing data with a new key, the configuration is updated to read the key from the same source.
+func (k *keyNamespace) Init(ctx context.Context, source KeySource) error {
+	if k.Enabled() {
+		return errs.ErrAlreadyEncrypted
+	}
+
+	return k.rekey(ctx, source)
+}
+
+// Rotate re-encrypts the data with a new key, the configuration is updated to read the key from the new source.
+func (k *keyNamespace) Rotate(ctx context.Context, source KeySource) error {
+	if !k.Enabled() {
+		return errs.ErrNotEncrypted
+	}
+
+	if source.KeyFile != "" && sameFile(source.KeyFile, k.config.Encryption.KeyFile) {
+		return errs.New("the data is already encrypted with this key file, choose a new path to generate a new key")
+	}
+
+	return k.rekey(ctx, source)
+}
+
+// rekey re-encrypts everything in a single transaction, then compacts the database so no page still holds the data under the old key (or in plaintext).
+// Contents in the blob store are moved into the database first, blobs are never encrypted.
+func (k *keyNamespace) rekey(ctx context.Context, source KeySource) error {
+	cipher, key, err := source.open()
+	if err != nil {
+		return err
+	}
+
+	err = k.db.RunInTx(ctx, &sql.TxOptions{Isolation: 0, ReadOnly: false}, func(ctx context.Context, tx bun.Tx) error {
+		return k.repository.Encryption().Rekey(ctx, tx, k.repository.Cipher(), cipher, key, k.blobs)
+	})
+	if err != nil {
+		return errs.Wrap(err, "failed to re-encrypt data")
+	}
+
+	k.repository = repository.NewRepository(k.db, cipher)
+	k.manager = events.NewManager(k.db, k.repository)
+
+	k.config.Encryption.KeyFile = source.KeyFile
+
+	if err := database.Compact(ctx, k.db); err != nil {
+		return errs.Wrap(err, "data was re-encrypted, but the database could not be compacted")
+	}
+
+	// Contents that were kept in the blob store now live (encrypted) in the database, so every blob can go.
+	if _, err := k.Clipboard().collectContents(ctx, time.Now()); err != nil {
+		return errs.Wrap(err, "data was re-encrypted, but the plaintext blobs could not be removed")
+	}
+
+	return nil
+}
+
+// open creates the cipher for a new key along with the row describing it.
+func (s KeySource) open() (*encryption.Cipher, *models.Encryption, error) {
+	var (
+		key    []byte
+		record = &models.Encryption{KeySource: models.KeySourceKeyFile}
+		err    error
+	)
+
+	if s.KeyFile != "" {
+		key, err = readOrCreateKeyFile(s.KeyFile)
+	} else {
+		record.KeySource = models.KeySourcePassphrase
+		if record.Salt, err = encryption.NewSalt(); err != nil {
+			return nil, nil, err
+		}
+
+		key, err = encryption.DeriveKey(s.Passphrase, record.Salt)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cipher, err := encryption.New(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if record.Verifier, err = cipher.Verifier(); err != nil {
+		return nil, nil, err
+	}
+
+	return cipher, record, nil
+}
+
+// loadCipher returns the cipher for an encrypted database, or nil if it is not encrypted.
+func loadCipher(ctx context.Context, db *bun.DB, config EncryptionConfig) (*encryption.Cipher, error) {
+	record, err := repository.NewRepository(db, nil).Encryption().Find(ctx)
+	if err != nil || record == nil {
+		return nil, err
+	}
+
+	var key []byte
+	switch record.KeySource {
+	case models.KeySourceKeyFile:
+		if strings.TrimSpace(config.KeyFile) == "" {
+			return nil, errs.ErrEncryptionKeyMissing.WithError(
+				errs.New("set encryption.key_file in the configuration"),
+			)
+		}
+
+		data, err := os.ReadFile(config.KeyFile)
+		if err != nil {
+			return nil, errs.ErrEncryptionKeyMissing.WithError(err)
+		}
+
+		if key, err = encryption.DecodeKey(data); err != nil {
+			return nil, err
+		}
+	default:
+		passphrase := config.ReadPassphrase()
+		if passphrase == "" {
+			return nil, errs.ErrEncryptionKeyMissing.WithError(
+				errs.New("set the passphrase in $" + config.PassphraseEnvName()),
+			)
+		}
+
+		if key, err = encryption.DeriveKey([]byte(passphrase), record.Salt); err != nil {
+			return nil, err
+		}
+	}
+
+	cipher, err := encryption.New(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cipher.Verify(record.Verifier); err != nil {
+		return nil, err
+	}
+
+	return cipher, nil
+}
+
+// readOrCreateKeyFile reads the key in the file, or generates one and writes it there (readable by the owner only) if the file does not exist.
+func readOrCreateKeyFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		return encryption.DecodeKey(data)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, errs.Wrap(err, "failed to read key file")
+	}
+
+	key, err := encryption.NewKey()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, errs.Wrap(err, "failed to create key file directory")
+	}
+
+	if err := os.WriteFile(path, []byte(encryption.EncodeKey(key)), 0o600); err != nil {
+		return nil, errs.Wrap(err, "failed to write key file")
+	}
+
+	return key, nil
+}
+
+func sameFile(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	if errA != nil || errB != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+
+	return os.SameFile(infoA, infoB)
+}
@@ -0,0 +1,4 @@
+CREATE INDEX IF NOT EXISTS idx_items_last_applied_sequence_id ON items(last_applied_sequence_id);
+
+-- bun:split
+CREATE INDEX IF NOT EXISTS idx_items_mimetype ON items(mimetype);
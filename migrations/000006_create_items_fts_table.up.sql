@@ -0,0 +1,9 @@
+CREATE VIRTUAL TABLE IF NOT EXISTS items_fts USING fts5(
+	content,
+	item_id UNINDEXED,
+	tokenize = 'unicode61 remove_diacritics 2'
+);
+
+-- bun:split
+-- Backfill the index with items created before full-text search existed
+INSERT INTO items_fts (content, item_id) SELECT content, id FROM items;
@@ -0,0 +1,4 @@
+ALTER TABLE items ADD COLUMN pinned_at TIMESTAMP;
+
+-- bun:split
+CREATE INDEX IF NOT EXISTS idx_items_pinned_at ON items(pinned_at);
@@ -0,0 +1,10 @@
+ALTER TABLE items ADD COLUMN expires_at TIMESTAMP;
+
+-- bun:split
+ALTER TABLE items ADD COLUMN max_pastes INTEGER;
+
+-- bun:split
+ALTER TABLE items ADD COLUMN paste_count INTEGER NOT NULL DEFAULT 0;
+
+-- bun:split
+CREATE INDEX IF NOT EXISTS idx_items_expires_at ON items(expires_at);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_undo_entries_undone_at;
+
+-- bun:split
+DROP TABLE IF EXISTS undo_entries;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS undo_entries (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	description TEXT NOT NULL,
+
+	undo_events BLOB NOT NULL CHECK (json_valid(undo_events)), -- compensating events that revert the operation
+	redo_events BLOB NOT NULL CHECK (json_valid(redo_events)), -- events that apply the operation again
+
+	undone_at TIMESTAMP, -- set while the entry is on the redo stack
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- bun:split
+CREATE INDEX IF NOT EXISTS idx_undo_entries_undone_at ON undo_entries(undone_at);
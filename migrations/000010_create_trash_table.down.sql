@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_trash_deleted_at;
+
+-- bun:split
+DROP INDEX IF EXISTS idx_trash_sequence_id;
+
+-- bun:split
+DROP INDEX IF EXISTS idx_trash_aggregate;
+
+-- bun:split
+DROP TABLE IF EXISTS trash;
@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS trash (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+
+	aggregate_type TEXT NOT NULL, -- 'item' or 'collection'
+	aggregate_id TEXT NOT NULL,
+	sequence_id INTEGER NOT NULL, -- sequence of the event that deleted the row, items deleted with their collection share it
+
+	-- collections
+	name TEXT,
+
+	-- items
+	collection_id TEXT,
+	content BLOB,
+	hash TEXT,
+	mimetype TEXT,
+	pinned_at TIMESTAMP,
+	expires_at TIMESTAMP,
+	max_pastes INTEGER,
+	paste_count INTEGER NOT NULL DEFAULT 0,
+	last_applied_sequence_id INTEGER, -- restored as-is so items return to their place in the history
+	updated_at TIMESTAMP,
+
+	created_at TIMESTAMP NOT NULL,
+	deleted_at TIMESTAMP NOT NULL
+);
+
+-- bun:split
+CREATE INDEX IF NOT EXISTS idx_trash_aggregate ON trash(aggregate_type, aggregate_id);
+
+-- bun:split
+CREATE INDEX IF NOT EXISTS idx_trash_sequence_id ON trash(sequence_id);
+
+-- bun:split
+CREATE INDEX IF NOT EXISTS idx_trash_deleted_at ON trash(deleted_at);
@@ -0,0 +1,11 @@
+-- Holds at most one row, describing the key the contents and event payloads are encrypted with.
+CREATE TABLE IF NOT EXISTS encryption (
+	id INTEGER PRIMARY KEY CHECK (id = 1),
+
+	key_source TEXT NOT NULL, -- 'passphrase' or 'key_file'
+	salt BLOB, -- only set for passphrases
+	verifier BLOB NOT NULL, -- a known value sealed with the key, used to reject a wrong key up front
+
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	rotated_at TIMESTAMP
+);
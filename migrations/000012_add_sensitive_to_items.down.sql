@@ -0,0 +1,4 @@
+ALTER TABLE trash DROP COLUMN sensitive;
+
+-- bun:split
+ALTER TABLE items DROP COLUMN sensitive;
@@ -0,0 +1,4 @@
+ALTER TABLE items ADD COLUMN sensitive BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- bun:split
+ALTER TABLE trash ADD COLUMN sensitive BOOLEAN NOT NULL DEFAULT FALSE;
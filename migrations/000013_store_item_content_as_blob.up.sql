@@ -0,0 +1,63 @@
+-- SQLite cannot change the type of a column, so the table is rebuilt with content declared as a BLOB.
+-- Contents that were stored as text are cast to BLOB, the bytes are kept as-is.
+CREATE TABLE items_new (
+	id TEXT PRIMARY KEY NOT NULL,
+	content BLOB NOT NULL,
+	hash TEXT NOT NULL,
+	mimetype TEXT NOT NULL DEFAULT 'text/plain',
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	last_applied_sequence_id INTEGER,
+	collection_id TEXT REFERENCES collections(id) ON DELETE CASCADE,
+	pinned_at TIMESTAMP,
+	expires_at TIMESTAMP,
+	max_pastes INTEGER,
+	paste_count INTEGER NOT NULL DEFAULT 0,
+	sensitive BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+-- bun:split
+INSERT INTO items_new (
+	id, content, hash, mimetype, created_at, updated_at, last_applied_sequence_id, collection_id,
+	pinned_at, expires_at, max_pastes, paste_count, sensitive
+)
+SELECT
+	id, CAST(content AS BLOB), hash, mimetype, created_at, updated_at, last_applied_sequence_id, collection_id,
+	pinned_at, expires_at, max_pastes, paste_count, sensitive
+FROM items;
+
+-- bun:split
+DROP TABLE items;
+
+-- bun:split
+ALTER TABLE items_new RENAME TO items;
+
+-- bun:split
+CREATE INDEX IF NOT EXISTS idx_items_collection_id ON items(collection_id);
+
+-- bun:split
+CREATE INDEX IF NOT EXISTS idx_items_created_at ON items(created_at);
+
+-- bun:split
+CREATE UNIQUE INDEX IF NOT EXISTS idx_items_unique_collection_hash ON items(collection_id, hash);
+
+-- bun:split
+CREATE INDEX IF NOT EXISTS idx_items_last_applied_sequence_id ON items(last_applied_sequence_id);
+
+-- bun:split
+CREATE INDEX IF NOT EXISTS idx_items_mimetype ON items(mimetype);
+
+-- bun:split
+CREATE INDEX IF NOT EXISTS idx_items_pinned_at ON items(pinned_at);
+
+-- bun:split
+CREATE INDEX IF NOT EXISTS idx_items_expires_at ON items(expires_at);
+
+-- bun:split
+CREATE TRIGGER trg_update_items_updated_at
+AFTER UPDATE ON items
+FOR EACH ROW
+BEGIN
+	UPDATE items SET updated_at = CURRENT_TIMESTAMP WHERE id = OLD.id;
+end
+;
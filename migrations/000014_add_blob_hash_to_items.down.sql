@@ -0,0 +1,14 @@
+-- Items stored in the blob store are left with empty contents, their files stay in the data directory.
+ALTER TABLE trash DROP COLUMN blob_size;
+
+-- bun:split
+ALTER TABLE trash DROP COLUMN blob_hash;
+
+-- bun:split
+DROP INDEX IF EXISTS idx_items_blob_hash;
+
+-- bun:split
+ALTER TABLE items DROP COLUMN blob_size;
+
+-- bun:split
+ALTER TABLE items DROP COLUMN blob_hash;
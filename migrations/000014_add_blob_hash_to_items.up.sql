@@ -0,0 +1,14 @@
+-- Items larger than the configured threshold keep their content in the blob store, these columns reference it.
+ALTER TABLE items ADD COLUMN blob_hash TEXT;
+
+-- bun:split
+ALTER TABLE items ADD COLUMN blob_size INTEGER;
+
+-- bun:split
+CREATE INDEX IF NOT EXISTS idx_items_blob_hash ON items(blob_hash);
+
+-- bun:split
+ALTER TABLE trash ADD COLUMN blob_hash TEXT;
+
+-- bun:split
+ALTER TABLE trash ADD COLUMN blob_size INTEGER;
@@ -0,0 +1,410 @@
+package migrations
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/uptrace/bun"
+	"go.trulyao.dev/bore/v2/pkg/encryption"
+	"go.trulyao.dev/bore/v2/pkg/lib"
+)
+
+// Every unique content is stored once in the contents table, keyed by the hash items already store (the keyed hash if the database is encrypted).
+// Items and trash entries keep their hash column as the reference, events and undo entries reference contents through a content_hash outside their payloads.
+//
+// This migration is written in Go since payloads carry their content base64-encoded, which SQLite can neither decode nor hash.
+// Encrypted payloads can not be read without the key, they keep their content inline until the database is rekeyed, see createContentsTable.
+func init() {
+	Migrations.MustRegister(createContentsTable, dropContentsTable)
+}
+
+// contentActions are the event types whose payloads reference content.
+var contentActions = []string{"create_item", "update_item_content"}
+
+const createContentsTableQuery = `
+CREATE TABLE IF NOT EXISTS contents (
+	hash TEXT PRIMARY KEY NOT NULL,
+	content BLOB NOT NULL, -- empty if the content is kept in the blob store
+	blob_hash TEXT,
+	size INTEGER NOT NULL, -- of the plaintext, wherever it is stored
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+)`
+
+func createContentsTable(ctx context.Context, db *bun.DB) error {
+	return db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		encrypted, err := isEncrypted(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		// Sealed contents are longer than their plaintext by a fixed overhead.
+		overhead := 0
+		if encrypted {
+			overhead = encryption.Overhead
+		}
+
+		if _, err := tx.ExecContext(ctx, createContentsTableQuery); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT OR IGNORE INTO contents (hash, content, blob_hash, size)
+			SELECT hash, content, blob_hash, COALESCE(blob_size, MAX(LENGTH(CAST(content AS BLOB)) - ?, 0)) FROM items`,
+			overhead,
+		)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT OR IGNORE INTO contents (hash, content, blob_hash, size)
+			SELECT hash, content, blob_hash, COALESCE(blob_size, MAX(LENGTH(CAST(content AS BLOB)) - ?, 0)) FROM trash
+			WHERE aggregate_type = 'item' AND hash IS NOT NULL AND content IS NOT NULL`,
+			overhead,
+		)
+		if err != nil {
+			return err
+		}
+
+		err = execAll(ctx, tx,
+			`DROP INDEX IF EXISTS idx_items_blob_hash`,
+			`ALTER TABLE items DROP COLUMN content`,
+			`ALTER TABLE items DROP COLUMN blob_hash`,
+			`ALTER TABLE items DROP COLUMN blob_size`,
+			`ALTER TABLE trash DROP COLUMN content`,
+			`ALTER TABLE trash DROP COLUMN blob_hash`,
+			`ALTER TABLE trash DROP COLUMN blob_size`,
+			`ALTER TABLE events ADD COLUMN content_hash TEXT`,
+			// Contents are looked up by hash alone when collecting unreferenced ones.
+			`CREATE INDEX IF NOT EXISTS idx_items_hash ON items(hash)`,
+			`CREATE INDEX IF NOT EXISTS idx_trash_hash ON trash(hash)`,
+			`CREATE INDEX IF NOT EXISTS idx_events_content_hash ON events(content_hash)`,
+		)
+		if err != nil {
+			return err
+		}
+
+		// Encrypted payloads can not be opened here, they keep their content inline (which still decodes, see payload.CreateItem.Content).
+		// They are converted the next time the database is rekeyed with `bore key rotate`, see referenceContent in database/repository/encryption.go.
+		if encrypted {
+			return nil
+		}
+
+		return rewritePayloads(ctx, tx, referencePayloadContent)
+	})
+}
+
+// dropContentsTable copies contents back into items, trash entries and payloads.
+// Encrypted payloads are left as they are, those that only reference their content are left without it.
+func dropContentsTable(ctx context.Context, db *bun.DB) error {
+	return db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		encrypted, err := isEncrypted(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		err = execAll(ctx, tx,
+			`ALTER TABLE items ADD COLUMN content BLOB NOT NULL DEFAULT x''`,
+			`ALTER TABLE items ADD COLUMN blob_hash TEXT`,
+			`ALTER TABLE items ADD COLUMN blob_size INTEGER`,
+			`ALTER TABLE trash ADD COLUMN content BLOB`,
+			`ALTER TABLE trash ADD COLUMN blob_hash TEXT`,
+			`ALTER TABLE trash ADD COLUMN blob_size INTEGER`,
+			`UPDATE items SET
+				content = (SELECT c.content FROM contents AS c WHERE c.hash = items.hash),
+				blob_hash = (SELECT c.blob_hash FROM contents AS c WHERE c.hash = items.hash),
+				blob_size = (SELECT c.size FROM contents AS c WHERE c.hash = items.hash AND c.blob_hash IS NOT NULL)
+			WHERE EXISTS (SELECT 1 FROM contents AS c WHERE c.hash = items.hash)`,
+			`UPDATE trash SET
+				content = (SELECT c.content FROM contents AS c WHERE c.hash = trash.hash),
+				blob_hash = (SELECT c.blob_hash FROM contents AS c WHERE c.hash = trash.hash),
+				blob_size = (SELECT c.size FROM contents AS c WHERE c.hash = trash.hash AND c.blob_hash IS NOT NULL)
+			WHERE aggregate_type = 'item'`,
+			`CREATE INDEX IF NOT EXISTS idx_items_blob_hash ON items(blob_hash)`,
+		)
+		if err != nil {
+			return err
+		}
+
+		if !encrypted {
+			if err := rewritePayloads(ctx, tx, inlinePayloadContent); err != nil {
+				return err
+			}
+		}
+
+		return execAll(ctx, tx,
+			`DROP INDEX IF EXISTS idx_events_content_hash`,
+			`DROP INDEX IF EXISTS idx_trash_hash`,
+			`DROP INDEX IF EXISTS idx_items_hash`,
+			`ALTER TABLE events DROP COLUMN content_hash`,
+			`DROP TABLE IF EXISTS contents`,
+		)
+	})
+}
+
+// isEncrypted reports whether the database has an encryption key.
+func isEncrypted(ctx context.Context, tx bun.Tx) (bool, error) {
+	return tx.NewSelect().Table("encryption").Exists(ctx)
+}
+
+func execAll(ctx context.Context, tx bun.Tx, queries ...string) error {
+	for _, query := range queries {
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rewritePayloads applies the rewrite to every payload of an event that references content, in the event log and on the undo stack.
+// The rewrite returns the new payload and the hash it references, if any.
+func rewritePayloads(
+	ctx context.Context,
+	tx bun.Tx,
+	rewrite func(ctx context.Context, tx bun.Tx, fields map[string]json.RawMessage) (string, error),
+) error {
+	var rows []struct {
+		ID      string          `bun:"event_id"`
+		Payload json.RawMessage `bun:"payload"`
+	}
+	err := tx.NewSelect().
+		Table("events").
+		Column("event_id", "payload").
+		Where("type IN (?)", bun.In(contentActions)).
+		Scan(ctx, &rows)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		payload, hash, err := rewritePayload(ctx, tx, row.Payload, rewrite)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.NewUpdate().
+			Table("events").
+			Set("payload = ?", string(payload)).
+			Set("content_hash = ?", sql.NullString{String: hash, Valid: hash != ""}).
+			Where("event_id = ?", row.ID).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	var entries []struct {
+		ID         int64           `bun:"id"`
+		UndoEvents json.RawMessage `bun:"undo_events"`
+		RedoEvents json.RawMessage `bun:"redo_events"`
+	}
+	if err := tx.NewSelect().Table("undo_entries").Column("id", "undo_events", "redo_events").Scan(ctx, &entries); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		undo, err := rewriteUndoEvents(ctx, tx, entry.UndoEvents, rewrite)
+		if err != nil {
+			return err
+		}
+
+		redo, err := rewriteUndoEvents(ctx, tx, entry.RedoEvents, rewrite)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.NewUpdate().
+			Table("undo_entries").
+			Set("undo_events = ?", string(undo)).
+			Set("redo_events = ?", string(redo)).
+			Where("id = ?", entry.ID).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func rewriteUndoEvents(
+	ctx context.Context,
+	tx bun.Tx,
+	data json.RawMessage,
+	rewrite func(ctx context.Context, tx bun.Tx, fields map[string]json.RawMessage) (string, error),
+) (json.RawMessage, error) {
+	var events []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &events); err != nil {
+		return nil, err
+	}
+
+	for _, event := range events {
+		var eventType string
+		if err := json.Unmarshal(event["type"], &eventType); err != nil {
+			return nil, err
+		}
+
+		delete(event, "content_hash")
+		if !isContentAction(eventType) {
+			continue
+		}
+
+		payload, hash, err := rewritePayload(ctx, tx, event["payload"], rewrite)
+		if err != nil {
+			return nil, err
+		}
+
+		event["payload"] = payload
+		if hash != "" {
+			if event["content_hash"], err = json.Marshal(hash); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return json.Marshal(events)
+}
+
+func rewritePayload(
+	ctx context.Context,
+	tx bun.Tx,
+	payload json.RawMessage,
+	rewrite func(ctx context.Context, tx bun.Tx, fields map[string]json.RawMessage) (string, error),
+) (json.RawMessage, string, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return nil, "", err
+	}
+
+	hash, err := rewrite(ctx, tx, fields)
+	if err != nil {
+		return nil, "", err
+	}
+
+	payload, err = json.Marshal(fields)
+	return payload, hash, err
+}
+
+// referencePayloadContent moves the content out of a payload into the contents table, replacing it with its checksum.
+// Payloads of contents in the blob store already reference them by hash, and redacted payloads reference nothing.
+func referencePayloadContent(ctx context.Context, tx bun.Tx, fields map[string]json.RawMessage) (string, error) {
+	var (
+		content  []byte
+		blobHash string
+		size     int64
+	)
+	if err := unmarshalFields(fields, map[string]any{"content": &content, "blob_hash": &blobHash, "size": &size}); err != nil {
+		return "", err
+	}
+
+	delete(fields, "content")
+	delete(fields, "blob_hash")
+	delete(fields, "size")
+
+	hash := blobHash
+	switch {
+	case blobHash != "":
+		_, err := tx.ExecContext(ctx,
+			"INSERT OR IGNORE INTO contents (hash, content, blob_hash, size) VALUES (?, ?, ?, ?)",
+			blobHash, []byte{}, blobHash, size,
+		)
+		if err != nil {
+			return "", err
+		}
+	case content != nil:
+		hash = lib.ComputeChecksum(content)
+		_, err := tx.ExecContext(ctx,
+			"INSERT OR IGNORE INTO contents (hash, content, size) VALUES (?, ?, ?)",
+			hash, content, len(content),
+		)
+		if err != nil {
+			return "", err
+		}
+	default:
+		return "", nil
+	}
+
+	var err error
+	fields["content_hash"], err = json.Marshal(hash)
+	return hash, err
+}
+
+// inlinePayloadContent puts the content a payload references back into it, contents in the blob store are referenced by their blob hash and size again.
+func inlinePayloadContent(ctx context.Context, tx bun.Tx, fields map[string]json.RawMessage) (string, error) {
+	var hash string
+	if err := unmarshalFields(fields, map[string]any{"content_hash": &hash}); err != nil {
+		return "", err
+	}
+
+	delete(fields, "content_hash")
+	fields["content"] = json.RawMessage("null")
+	if hash == "" {
+		return "", nil
+	}
+
+	var content struct {
+		Content  []byte         `bun:"content"`
+		BlobHash sql.NullString `bun:"blob_hash"`
+		Size     int64          `bun:"size"`
+	}
+	err := tx.NewSelect().
+		Table("contents").
+		Column("content", "blob_hash", "size").
+		Where("hash = ?", hash).
+		Scan(ctx, &content)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	if content.BlobHash.Valid {
+		fields["content"] = json.RawMessage(`""`)
+		return "", marshalFields(fields, map[string]any{"blob_hash": content.BlobHash.String, "size": content.Size})
+	}
+
+	return "", marshalFields(fields, map[string]any{"content": content.Content})
+}
+
+func marshalFields(fields map[string]json.RawMessage, values map[string]any) error {
+	for name, value := range values {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+
+		fields[name] = raw
+	}
+
+	return nil
+}
+
+func unmarshalFields(fields map[string]json.RawMessage, targets map[string]any) error {
+	for name, target := range targets {
+		raw, ok := fields[name]
+		if !ok || bytes.Equal(raw, []byte("null")) {
+			continue
+		}
+
+		if err := json.Unmarshal(raw, target); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func isContentAction(eventType string) bool {
+	for _, action := range contentActions {
+		if action == eventType {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,323 @@
+package migrations_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	"github.com/uptrace/bun/driver/sqliteshim"
+	"github.com/uptrace/bun/migrate"
+	"go.trulyao.dev/bore/v2/database/models"
+	"go.trulyao.dev/bore/v2/database/repository"
+	"go.trulyao.dev/bore/v2/migrations"
+	"go.trulyao.dev/bore/v2/pkg/blobstore"
+	"go.trulyao.dev/bore/v2/pkg/encryption"
+	"go.trulyao.dev/bore/v2/pkg/lib"
+)
+
+// openDB opens an empty database with every migration up to and including the named one applied.
+func openDB(t *testing.T, name string) *bun.DB {
+	t.Helper()
+
+	sqldb, err := sql.Open(sqliteshim.ShimName, "file:"+filepath.Join(t.TempDir(), "data.db")+"?_foreign_keys=on")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	db := bun.NewDB(sqldb, sqlitedialect.New())
+	t.Cleanup(func() { _ = db.Close() })
+
+	applied := migrate.NewMigrations()
+	for _, migration := range migrations.Migrations.Sorted() {
+		if migration.Name <= name {
+			applied.Add(migration)
+		}
+	}
+
+	migrator := migrate.NewMigrator(db, applied)
+	if err := migrator.Init(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if _, err := migrator.Migrate(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	return db
+}
+
+// migrateUp applies the remaining migrations, migrateDown rolls back the last ones applied.
+func migrateUp(t *testing.T, db *bun.DB) {
+	t.Helper()
+
+	if _, err := migrate.NewMigrator(db, migrations.Migrations).Migrate(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func migrateDown(t *testing.T, db *bun.DB) {
+	t.Helper()
+
+	if _, err := migrate.NewMigrator(db, migrations.Migrations).Rollback(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func exec(t *testing.T, db *bun.DB, query string, args ...any) {
+	t.Helper()
+
+	if _, err := db.ExecContext(context.Background(), query, args...); err != nil {
+		t.Fatalf("%s: expected no error, got %v", query, err)
+	}
+}
+
+func queryValue[T any](t *testing.T, db *bun.DB, query string, args ...any) T {
+	t.Helper()
+
+	var value T
+	if err := db.QueryRowContext(context.Background(), query, args...).Scan(&value); err != nil {
+		t.Fatalf("%s: expected no error, got %v", query, err)
+	}
+
+	return value
+}
+
+func encode(content string) string {
+	return base64.StdEncoding.EncodeToString([]byte(content))
+}
+
+func payloadFields(t *testing.T, payload string) map[string]any {
+	t.Helper()
+
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(payload), &fields); err != nil {
+		t.Fatalf("expected a JSON payload, got %q (%v)", payload, err)
+	}
+
+	return fields
+}
+
+func Test_CreateContentsTable(t *testing.T) {
+	db := openDB(t, "000014")
+
+	hello, edited, gone := lib.ComputeChecksum([]byte("hello")), lib.ComputeChecksum([]byte("edited")), lib.ComputeChecksum([]byte("gone"))
+	blob := lib.ComputeChecksum([]byte("a large content in the blob store"))
+
+	exec(t, db, "INSERT INTO collections (id, name) VALUES ('work', 'work')")
+	exec(t, db, "INSERT INTO items (id, content, hash) VALUES ('a', ?, ?), ('c', ?, ?)", []byte("hello"), hello, []byte("hello"), hello)
+	exec(t, db, "UPDATE items SET collection_id = 'work' WHERE id = 'c'")
+	exec(t, db, "INSERT INTO items (id, content, hash, blob_hash, blob_size) VALUES ('b', x'', ?, ?, 5000)", blob, blob)
+	exec(t, db, `INSERT INTO trash (aggregate_type, aggregate_id, sequence_id, content, hash, created_at, deleted_at)
+		VALUES ('item', 'd', 4, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`, []byte("gone"), gone)
+	exec(t, db, `INSERT INTO trash (aggregate_type, aggregate_id, sequence_id, name, created_at, deleted_at)
+		VALUES ('collection', 'e', 5, 'old', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`)
+
+	for i, event := range []struct{ aggregateID, eventType, payload string }{
+		{"a", "create_item", `{"content":"` + encode("hello") + `","mimetype":"text/plain","collection_id":""}`},
+		{"b", "create_item", `{"content":"","blob_hash":"` + blob + `","size":5000,"mimetype":"text/plain","collection_id":""}`},
+		{"a", "update_item_content", `{"content":"` + encode("edited") + `"}`},
+		{"d", "create_item", `{"content":null,"mimetype":"text/plain","collection_id":""}`},
+		{"a", "pin_item", `{}`},
+	} {
+		exec(t, db, `INSERT INTO events (event_id, aggregate_type, aggregate_id, aggregate_version, type, payload)
+			VALUES (?, 'item', ?, ?, ?, ?)`, i+1, event.aggregateID, i+1, event.eventType, event.payload)
+	}
+
+	exec(t, db, "INSERT INTO undo_entries (description, undo_events, redo_events) VALUES ('copy item a', ?, ?)",
+		`[{"aggregate_type":"item","aggregate_id":"a","type":"delete_item","payload":{}}]`,
+		`[{"aggregate_type":"item","aggregate_id":"a","type":"create_item","payload":{"content":"`+encode("hello")+`","mimetype":"text/plain","collection_id":""}}]`,
+	)
+
+	migrated := func(t *testing.T) {
+		contents := map[string]string{hello: "hello", edited: "edited", gone: "gone", blob: ""}
+		if count := queryValue[int](t, db, "SELECT COUNT(*) FROM contents"); count != len(contents) {
+			t.Errorf("expected %d contents, got %d", len(contents), count)
+		}
+
+		for hash, content := range contents {
+			if stored := queryValue[[]byte](t, db, "SELECT content FROM contents WHERE hash = ?", hash); string(stored) != content {
+				t.Errorf("expected content %q for %s, got %q", content, hash, stored)
+			}
+		}
+
+		if size := queryValue[int](t, db, "SELECT size FROM contents WHERE blob_hash = ?", blob); size != 5000 {
+			t.Errorf("expected the blob to keep its size, got %d", size)
+		}
+
+		for id, want := range map[int]string{1: hello, 2: blob, 3: edited, 4: "", 5: ""} {
+			hash := queryValue[sql.NullString](t, db, "SELECT content_hash FROM events WHERE event_id = ?", id)
+			fields := payloadFields(t, queryValue[string](t, db, "SELECT payload FROM events WHERE event_id = ?", id))
+
+			if _, ok := fields["content"]; ok || hash.String != want || (want != "" && fields["content_hash"] != want) {
+				t.Errorf("event %d: expected a reference to %q, got %v (%v)", id, want, fields, hash)
+			}
+		}
+
+		var redo []map[string]any
+		if err := json.Unmarshal(queryValue[[]byte](t, db, "SELECT redo_events FROM undo_entries"), &redo); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if payload := redo[0]["payload"].(map[string]any); redo[0]["content_hash"] != hello || payload["content"] != nil {
+			t.Errorf("expected the undo entry to reference %s, got %v", hello, redo[0])
+		}
+	}
+
+	migrateUp(t, db)
+	t.Run("up", migrated)
+
+	migrateDown(t, db)
+	t.Run("down", func(t *testing.T) {
+		for id, want := range map[string]string{"a": "hello", "b": "", "c": "hello"} {
+			if content := queryValue[[]byte](t, db, "SELECT content FROM items WHERE id = ?", id); string(content) != want {
+				t.Errorf("expected item %s to hold %q, got %q", id, want, content)
+			}
+		}
+
+		if size := queryValue[int](t, db, "SELECT blob_size FROM items WHERE blob_hash = ?", blob); size != 5000 {
+			t.Errorf("expected the blob size to be restored, got %d", size)
+		}
+
+		if content := queryValue[[]byte](t, db, "SELECT content FROM trash WHERE aggregate_id = 'd'"); string(content) != "gone" {
+			t.Errorf("expected the trash entry to hold its content, got %q", content)
+		}
+
+		for id, want := range map[int]any{1: encode("hello"), 2: "", 3: encode("edited"), 4: nil} {
+			fields := payloadFields(t, queryValue[string](t, db, "SELECT payload FROM events WHERE event_id = ?", id))
+			if content, ok := fields["content"]; !ok || content != want || fields["content_hash"] != nil {
+				t.Errorf("event %d: expected inline content %v, got %v", id, want, fields)
+			}
+		}
+
+		if fields := payloadFields(t, queryValue[string](t, db, "SELECT payload FROM events WHERE event_id = 2")); fields["blob_hash"] != blob {
+			t.Errorf("expected the blob reference to be restored, got %v", fields)
+		}
+
+		if redo := queryValue[string](t, db, "SELECT redo_events FROM undo_entries"); redo != `[{"aggregate_id":"a","aggregate_type":"item","payload":{"collection_id":"","content":"`+encode("hello")+`","mimetype":"text/plain"},"type":"create_item"}]` {
+			t.Errorf("expected the undo entry to hold its content, got %s", redo)
+		}
+
+		if exists := queryValue[bool](t, db, "SELECT EXISTS(SELECT 1 FROM sqlite_master WHERE name = 'contents')"); exists {
+			t.Error("expected the contents table to be dropped")
+		}
+	})
+
+	migrateUp(t, db)
+	t.Run("up again", migrated)
+}
+
+// Encrypted payloads can not be rewritten without the key, they are left for Rekey to convert.
+func Test_CreateContentsTable_Encrypted(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t, "000014")
+
+	key, err := encryption.NewKey()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	cipher, err := encryption.New(key)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	sealed, err := cipher.Seal([]byte("hello"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	payload, err := cipher.SealJSON(json.RawMessage(`{"content":"` + encode("hello") + `","mimetype":"text/plain","collection_id":""}`))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	checksum := lib.ComputeChecksum([]byte("hello"))
+	exec(t, db, "INSERT INTO encryption (id, key_source, verifier) VALUES (1, 'key_file', x'00')")
+	exec(t, db, "INSERT INTO items (id, content, hash) VALUES ('a', ?, ?)", sealed, cipher.Hash(checksum))
+	exec(t, db, `INSERT INTO events (event_id, aggregate_type, aggregate_id, aggregate_version, type, payload)
+		VALUES ('1', 'item', 'a', 1, 'create_item', ?)`, string(payload))
+	exec(t, db, "INSERT INTO undo_entries (description, undo_events, redo_events) VALUES ('copy item a', '[]', ?)",
+		`[{"aggregate_type":"item","aggregate_id":"a","type":"create_item","payload":`+string(payload)+`}]`,
+	)
+
+	migrated := func(t *testing.T) {
+		if size := queryValue[int](t, db, "SELECT size FROM contents WHERE hash = ?", cipher.Hash(checksum)); size != len("hello") {
+			t.Errorf("expected the size of the plaintext, got %d", size)
+		}
+
+		if stored := queryValue[string](t, db, "SELECT payload FROM events"); stored != string(payload) {
+			t.Errorf("expected the sealed payload to be left as-is, got %s", stored)
+		}
+
+		if hash := queryValue[sql.NullString](t, db, "SELECT content_hash FROM events"); hash.Valid {
+			t.Errorf("expected no content reference, got %s", hash.String)
+		}
+	}
+
+	migrateUp(t, db)
+	t.Run("up", migrated)
+
+	migrateDown(t, db)
+	if content := queryValue[[]byte](t, db, "SELECT content FROM items"); string(content) != string(sealed) {
+		t.Errorf("expected the sealed content to be restored, got %q", content)
+	}
+
+	migrateUp(t, db)
+	t.Run("up again", migrated)
+
+	newKey, err := encryption.NewKey()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	to, err := encryption.New(newKey)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	verifier, err := to.Verifier()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	err = db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		key := &models.Encryption{KeySource: models.KeySourceKeyFile, Verifier: verifier}
+		return repository.NewRepository(db, cipher).Encryption().Rekey(ctx, tx, cipher, to, key, blobstore.New(t.TempDir()))
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	t.Run("rekeyed", func(t *testing.T) {
+		if hash := queryValue[string](t, db, "SELECT content_hash FROM events"); hash != to.Hash(checksum) {
+			t.Errorf("expected the event to reference %s, got %s", to.Hash(checksum), hash)
+		}
+
+		opened, err := to.OpenJSON(json.RawMessage(queryValue[string](t, db, "SELECT payload FROM events")))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if fields := payloadFields(t, string(opened)); fields["content"] != nil || fields["content_hash"] != checksum {
+			t.Errorf("expected the payload to reference %s, got %v", checksum, fields)
+		}
+
+		var redo []models.UndoEvent
+		if err := json.Unmarshal(queryValue[[]byte](t, db, "SELECT redo_events FROM undo_entries"), &redo); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if redo[0].ContentHash != to.Hash(checksum) {
+			t.Errorf("expected the undo entry to reference %s, got %q", to.Hash(checksum), redo[0].ContentHash)
+		}
+
+		if count := queryValue[int](t, db, "SELECT COUNT(*) FROM contents"); count != 1 {
+			t.Errorf("expected 1 content, got %d", count)
+		}
+	})
+}
@@ -0,0 +1,172 @@
+// Package blobstore keeps contents as files on disk, named after the SHA-256 checksum of their bytes.
+package blobstore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.trulyao.dev/bore/v2/pkg/errs"
+)
+
+// tempPrefix marks blobs that are still being written, they are renamed to their checksum once complete.
+const tempPrefix = ".tmp-"
+
+var (
+	ErrBlobNotFound = errs.New("blob not found")
+	ErrInvalidHash  = errs.New("invalid blob hash")
+
+	hashPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+)
+
+// Store is a content-addressed store rooted at a directory.
+// Blobs live in a subdirectory named after the first two characters of their hash, so no single directory grows too large.
+type Store struct {
+	dir string
+}
+
+// New creates a store rooted at dir, the directory is only created once the first blob is written.
+func New(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Put streams the reader into the store and returns the checksum and size of what was read.
+// Content that is already stored is not written twice, the existing blob is kept (and counts as freshly written, see Collect).
+func (s *Store) Put(r io.Reader) (string, int64, error) {
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return "", 0, errs.Wrap(err, "failed to create blob directory")
+	}
+
+	temp, err := os.CreateTemp(s.dir, tempPrefix+"*")
+	if err != nil {
+		return "", 0, errs.Wrap(err, "failed to create blob")
+	}
+	defer func() { _ = os.Remove(temp.Name()) }()
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(temp, hash), r)
+	if err == nil {
+		err = temp.Sync()
+	}
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", 0, errs.Wrap(err, "failed to write blob")
+	}
+
+	checksum := hex.EncodeToString(hash.Sum(nil))
+	path := s.path(checksum)
+
+	if _, err := os.Stat(path); err == nil {
+		now := time.Now()
+		return checksum, size, os.Chtimes(path, now, now)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return "", 0, errs.Wrap(err, "failed to create blob directory")
+	}
+
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return "", 0, errs.Wrap(err, "failed to store blob")
+	}
+
+	return checksum, size, nil
+}
+
+// Open returns a reader for the blob with the given hash, the caller must close it.
+func (s *Store) Open(hash string) (*os.File, error) {
+	if !hashPattern.MatchString(hash) {
+		return nil, ErrInvalidHash
+	}
+
+	file, err := os.Open(s.path(hash))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrBlobNotFound
+	}
+
+	return file, err
+}
+
+// ReadAll returns the full content of the blob with the given hash.
+func (s *Store) ReadAll(hash string) ([]byte, error) {
+	file, err := s.Open(hash)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	return io.ReadAll(file)
+}
+
+// Remove deletes the blob with the given hash, ErrBlobNotFound is returned if there is none.
+func (s *Store) Remove(hash string) error {
+	if !hashPattern.MatchString(hash) {
+		return ErrInvalidHash
+	}
+
+	err := remove(s.path(hash))
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrBlobNotFound
+	}
+
+	return err
+}
+
+// Collect removes every blob whose hash is not referenced, along with leftovers of interrupted writes.
+// Only files last written before the cutoff are removed, so a blob that was just written but is not referenced by a committed row yet is kept.
+func (s *Store) Collect(referenced map[string]struct{}, before time.Time) (int, error) {
+	removed := 0
+	err := filepath.WalkDir(s.dir, func(path string, entry fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		} else if err != nil || entry.IsDir() {
+			return err
+		}
+
+		name := entry.Name()
+		if _, ok := referenced[name]; ok {
+			return nil
+		}
+
+		if !hashPattern.MatchString(name) && !strings.HasPrefix(name, tempPrefix) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil || !info.ModTime().Before(before) {
+			return err
+		}
+
+		if err := remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+
+		removed++
+		return nil
+	})
+
+	return removed, err
+}
+
+// remove deletes the file along with its directory if that is left empty.
+func remove(path string) error {
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+
+	// Fails if the directory still holds other blobs, which is fine.
+	_ = os.Remove(filepath.Dir(path))
+	return nil
+}
+
+func (s *Store) path(hash string) string {
+	return filepath.Join(s.dir, hash[:2], hash)
+}
@@ -0,0 +1,88 @@
+package blobstore_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.trulyao.dev/bore/v2/pkg/blobstore"
+	"go.trulyao.dev/bore/v2/pkg/lib"
+)
+
+func Test_PutAndOpen(t *testing.T) {
+	store := blobstore.New(t.TempDir())
+	content := []byte(strings.Repeat("a large log line\n", 1000))
+
+	hash, size, err := store.Put(bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if hash != lib.ComputeChecksum(content) || size != int64(len(content)) {
+		t.Fatalf("expected hash %s and size %d, got %s and %d", lib.ComputeChecksum(content), len(content), hash, size)
+	}
+
+	if again, _, err := store.Put(bytes.NewReader(content)); err != nil || again != hash {
+		t.Fatalf("expected the same content to be stored under %s, got %s (%v)", hash, again, err)
+	}
+
+	stored, err := store.ReadAll(hash)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !bytes.Equal(stored, content) {
+		t.Errorf("expected the stored content to match what was put")
+	}
+
+	if _, err := store.Open(lib.ComputeChecksum([]byte("missing"))); !errors.Is(err, blobstore.ErrBlobNotFound) {
+		t.Errorf("expected ErrBlobNotFound, got %v", err)
+	}
+
+	if _, err := store.Open("../../config.toml"); !errors.Is(err, blobstore.ErrInvalidHash) {
+		t.Errorf("expected ErrInvalidHash, got %v", err)
+	}
+}
+
+func Test_Collect(t *testing.T) {
+	store := blobstore.New(t.TempDir())
+
+	kept, _, err := store.Put(strings.NewReader("kept"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	unreferenced, _, err := store.Put(strings.NewReader("unreferenced"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	referenced := map[string]struct{}{kept: {}}
+
+	// Blobs written after the cutoff may belong to a copy that has not been committed yet.
+	if removed, err := store.Collect(referenced, time.Now().Add(-time.Hour)); err != nil || removed != 0 {
+		t.Fatalf("expected recent blobs to be kept, removed %d (%v)", removed, err)
+	}
+
+	if removed, err := store.Collect(referenced, time.Now().Add(time.Second)); err != nil || removed != 1 {
+		t.Fatalf("expected 1 blob to be removed, removed %d (%v)", removed, err)
+	}
+
+	if _, err := store.Open(unreferenced); !errors.Is(err, blobstore.ErrBlobNotFound) {
+		t.Errorf("expected the unreferenced blob to be removed, got %v", err)
+	}
+
+	if _, err := store.Open(kept); err != nil {
+		t.Errorf("expected the referenced blob to be kept, got %v", err)
+	}
+}
+
+func Test_CollectEmptyStore(t *testing.T) {
+	store := blobstore.New(t.TempDir() + "/blobs")
+
+	if removed, err := store.Collect(nil, time.Now()); err != nil || removed != 0 {
+		t.Errorf("expected a store without blobs to be left alone, removed %d (%v)", removed, err)
+	}
+}
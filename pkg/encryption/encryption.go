@@ -0,0 +1,234 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"go.trulyao.dev/bore/v2/pkg/errs"
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	// KeySize is the size of a master key in bytes.
+	KeySize = 32
+	// SaltSize is the size of the salt used to derive a key from a passphrase.
+	SaltSize = 16
+	// Overhead is the number of bytes Seal adds to the plaintext.
+	Overhead = 1 + nonceSize + tagSize
+
+	version   byte = 1
+	nonceSize      = 12
+	tagSize        = 16
+
+	// Argon2id parameters, following the second recommended option of RFC 9106.
+	kdfTime    = 3
+	kdfMemory  = 64 * 1024
+	kdfThreads = 4
+
+	// verifierPlaintext is sealed and stored alongside the data, so a wrong key is detected before anything is decrypted.
+	verifierPlaintext = "bore"
+)
+
+var (
+	ErrInvalidKey       = errs.New("invalid encryption key")
+	ErrMalformedData    = errs.New("encrypted data is malformed or was tampered with")
+	ErrUnsealedPayload  = errs.New("payload is not encrypted")
+	ErrEmptyPassphrase  = errs.New("passphrase cannot be empty")
+	ErrInvalidKeyLength = errs.New("encryption key must be 32 bytes")
+)
+
+// Cipher encrypts item contents and event payloads with AES-256-GCM, and keys content hashes with HMAC-SHA256 so they do not reveal the content either.
+// A nil *Cipher is valid and leaves everything untouched, so callers do not need to check whether encryption is enabled.
+type Cipher struct {
+	aead   cipher.AEAD
+	macKey []byte
+}
+
+// sealedPayload is the JSON envelope encrypted payloads are stored in, so they remain valid JSON.
+type sealedPayload struct {
+	Sealed []byte `json:"sealed"`
+}
+
+// New creates a cipher from a master key, the encryption and hashing keys are derived from it separately.
+func New(key []byte) (*Cipher, error) {
+	if len(key) != KeySize {
+		return nil, ErrInvalidKeyLength
+	}
+
+	encryptionKey, err := hkdf.Key(sha256.New, key, nil, "bore content encryption", KeySize)
+	if err != nil {
+		return nil, err
+	}
+
+	macKey, err := hkdf.Key(sha256.New, key, nil, "bore content hash", KeySize)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cipher{aead: aead, macKey: macKey}, nil
+}
+
+// NewKey returns a random master key.
+func NewKey() ([]byte, error) {
+	return randomBytes(KeySize)
+}
+
+// NewSalt returns a random salt for DeriveKey.
+func NewSalt() ([]byte, error) {
+	return randomBytes(SaltSize)
+}
+
+// DeriveKey derives a master key from a passphrase with Argon2id.
+func DeriveKey(passphrase []byte, salt []byte) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, ErrEmptyPassphrase
+	}
+
+	return argon2.IDKey(passphrase, salt, kdfTime, kdfMemory, kdfThreads, KeySize), nil
+}
+
+// EncodeKey encodes a master key for storage in a key file.
+func EncodeKey(key []byte) string {
+	return base64.StdEncoding.EncodeToString(key) + "\n"
+}
+
+// DecodeKey decodes the contents of a key file.
+func DecodeKey(data []byte) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, errs.Wrap(err, "key file is not valid base64")
+	}
+
+	if len(key) != KeySize {
+		return nil, ErrInvalidKeyLength
+	}
+
+	return key, nil
+}
+
+// Enabled reports whether the cipher encrypts anything.
+func (c *Cipher) Enabled() bool {
+	return c != nil
+}
+
+// Seal encrypts the plaintext, every call uses a fresh random nonce.
+func (c *Cipher) Seal(plaintext []byte) ([]byte, error) {
+	if c == nil {
+		return plaintext, nil
+	}
+
+	nonce, err := randomBytes(nonceSize)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed := make([]byte, 0, len(plaintext)+Overhead)
+	sealed = append(sealed, version)
+	sealed = append(sealed, nonce...)
+
+	return c.aead.Seal(sealed, nonce, plaintext, nil), nil
+}
+
+// Open decrypts data produced by Seal, it fails if the data was encrypted with another key or modified.
+func (c *Cipher) Open(data []byte) ([]byte, error) {
+	if c == nil {
+		return data, nil
+	}
+
+	if len(data) < Overhead || data[0] != version {
+		return nil, ErrMalformedData
+	}
+
+	plaintext, err := c.aead.Open(nil, data[1:1+nonceSize], data[1+nonceSize:], nil)
+	if err != nil {
+		return nil, ErrMalformedData
+	}
+
+	return plaintext, nil
+}
+
+// SealJSON encrypts a JSON document into a JSON envelope.
+func (c *Cipher) SealJSON(data json.RawMessage) (json.RawMessage, error) {
+	if c == nil {
+		return data, nil
+	}
+
+	sealed, err := c.Seal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(sealedPayload{Sealed: sealed})
+}
+
+// OpenJSON decrypts an envelope produced by SealJSON.
+func (c *Cipher) OpenJSON(data json.RawMessage) (json.RawMessage, error) {
+	if c == nil {
+		return data, nil
+	}
+
+	var envelope sealedPayload
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, ErrMalformedData
+	}
+
+	if envelope.Sealed == nil {
+		return nil, ErrUnsealedPayload
+	}
+
+	return c.Open(envelope.Sealed)
+}
+
+// Hash returns the value stored in place of a content checksum.
+// Without encryption this is the checksum itself, otherwise it is keyed so identical content can still be found without revealing what it is.
+func (c *Cipher) Hash(checksum string) string {
+	if c == nil {
+		return checksum
+	}
+
+	mac := hmac.New(sha256.New, c.macKey)
+	mac.Write([]byte(checksum))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verifier returns a value that Verify accepts only with the same key.
+func (c *Cipher) Verifier() ([]byte, error) {
+	return c.Seal([]byte(verifierPlaintext))
+}
+
+// Verify checks a verifier produced by Verifier, and returns ErrInvalidKey if it was created with another key.
+func (c *Cipher) Verify(verifier []byte) error {
+	plaintext, err := c.Open(verifier)
+	if err != nil || string(plaintext) != verifierPlaintext {
+		return ErrInvalidKey
+	}
+
+	return nil
+}
+
+func randomBytes(size int) ([]byte, error) {
+	data := make([]byte, size)
+	if _, err := rand.Read(data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
@@ -0,0 +1,139 @@
+package encryption_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"go.trulyao.dev/bore/v2/pkg/encryption"
+)
+
+func newCipher(t *testing.T) *encryption.Cipher {
+	t.Helper()
+
+	key, err := encryption.NewKey()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	cipher, err := encryption.New(key)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	return cipher
+}
+
+func Test_SealAndOpen(t *testing.T) {
+	cipher := newCipher(t)
+	plaintext := []byte("hello world")
+
+	sealed, err := cipher.Seal(plaintext)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if bytes.Contains(sealed, plaintext) || len(sealed) != len(plaintext)+encryption.Overhead {
+		t.Fatalf("expected %d sealed bytes without the plaintext, got %q", len(plaintext)+encryption.Overhead, sealed)
+	}
+
+	opened, err := cipher.Open(sealed)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !bytes.Equal(opened, plaintext) {
+		t.Errorf("expected %q, got %q", plaintext, opened)
+	}
+
+	sealed[len(sealed)-1] ^= 1
+	if _, err := cipher.Open(sealed); err == nil {
+		t.Error("expected tampered data to be rejected")
+	}
+
+	if _, err := newCipher(t).Open(sealed); err == nil {
+		t.Error("expected data sealed with another key to be rejected")
+	}
+}
+
+func Test_SealJSON(t *testing.T) {
+	cipher := newCipher(t)
+	payload := json.RawMessage(`{"content":"aGVsbG8="}`)
+
+	sealed, err := cipher.SealJSON(payload)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !json.Valid(sealed) || bytes.Contains(sealed, []byte("content")) {
+		t.Fatalf("expected an opaque JSON envelope, got %s", sealed)
+	}
+
+	opened, err := cipher.OpenJSON(sealed)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !bytes.Equal(opened, payload) {
+		t.Errorf("expected %s, got %s", payload, opened)
+	}
+
+	if _, err := cipher.OpenJSON(payload); err == nil {
+		t.Error("expected an unsealed payload to be rejected")
+	}
+}
+
+func Test_NilCipher(t *testing.T) {
+	var cipher *encryption.Cipher
+	data := []byte("hello world")
+
+	if cipher.Enabled() {
+		t.Error("expected a nil cipher to be disabled")
+	}
+
+	if sealed, _ := cipher.Seal(data); !bytes.Equal(sealed, data) {
+		t.Errorf("expected %q, got %q", data, sealed)
+	}
+
+	if hash := cipher.Hash("abc"); hash != "abc" {
+		t.Errorf("expected the checksum to be unchanged, got %q", hash)
+	}
+}
+
+func Test_Verify(t *testing.T) {
+	salt, err := encryption.NewSalt()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	derive := func(passphrase string) *encryption.Cipher {
+		key, err := encryption.DeriveKey([]byte(passphrase), salt)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		cipher, err := encryption.New(key)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		return cipher
+	}
+
+	verifier, err := derive("correct horse").Verifier()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := derive("correct horse").Verify(verifier); err != nil {
+		t.Errorf("expected the same passphrase to be accepted, got %v", err)
+	}
+
+	if err := derive("battery staple").Verify(verifier); err != encryption.ErrInvalidKey {
+		t.Errorf("expected ErrInvalidKey, got %v", err)
+	}
+
+	if derive("correct horse").Hash("abc") != derive("correct horse").Hash("abc") {
+		t.Error("expected hashes to be stable for the same key")
+	}
+}
@@ -0,0 +1,22 @@
+package errs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AmbiguousIDError is returned when a short ID prefix matches more than one record.
+type AmbiguousIDError struct {
+	Prefix     string
+	Candidates []string
+}
+
+func (e *AmbiguousIDError) Error() string {
+	return fmt.Sprintf(
+		"ambiguous id prefix %q, it matches: %s",
+		e.Prefix,
+		strings.Join(e.Candidates, ", "),
+	)
+}
+
+var _ error = (*AmbiguousIDError)(nil)
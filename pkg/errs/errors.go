@@ -14,6 +14,18 @@ var (
 	ErrFailedToCloseDB       = New("failed to close database connection")
 	ErrFailedToRemoveDataDir = New("failed to remove data directory")
 	ErrCollectionNotFound    = New("collection not found")
+	ErrItemNotFound          = New("item not found")
+	ErrItemPinned            = New("item is pinned, unpin it first or force the deletion")
+	ErrInvalidItemReference  = New("invalid item reference, expected @N or collection@N")
+	ErrNothingToUndo         = New("nothing to undo")
+	ErrNothingToRedo         = New("nothing to redo")
+	ErrTrashEntryNotFound    = New("nothing with that id in the trash")
+	ErrItemModified          = New("item was changed by someone else in the meantime")
+	ErrEncryptionKeyMissing  = New("the database is encrypted but no encryption key was provided")
+	ErrAlreadyEncrypted      = New("the database is already encrypted, use `bore key rotate` to change the key")
+	ErrNotEncrypted          = New("the database is not encrypted, run `bore key init` first")
+	ErrClipboardUnavailable  = New("system clipboard is not available")
+	ErrItemInBlobStore       = New("content above the blob threshold can not be edited or appended to")
 )
 
 func New(message string) *BoreError {
@@ -34,6 +46,12 @@ func (e *BoreError) WithError(err error) *BoreError {
 	return &BoreError{Message: e.Message, OriginalError: err}
 }
 
+// Is reports whether the error was derived from target with WithError, so sentinel errors still match once details are attached.
+func (e *BoreError) Is(target error) bool {
+	t, ok := target.(*BoreError)
+	return ok && t.OriginalError == nil && t.Message == e.Message
+}
+
 func (e *BoreError) Error() string {
 	if e.OriginalError == nil {
 		return e.Message
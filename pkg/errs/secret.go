@@ -0,0 +1,14 @@
+package errs
+
+import "fmt"
+
+// SecretDetectedError is returned when copied content matches a secret detection rule and the policy refuses to copy it.
+type SecretDetectedError struct {
+	Rule string
+}
+
+func (e *SecretDetectedError) Error() string {
+	return fmt.Sprintf("content looks like a secret (matched rule %q), refusing to copy it", e.Rule)
+}
+
+var _ error = (*SecretDetectedError)(nil)
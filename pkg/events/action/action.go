@@ -2,5 +2,5 @@ package action
 
 //go:generate go tool github.com/abice/go-enum --marshal
 
-// ENUM(create_item,bump_item,delete_item,create_collection,delete_collection,rename_collection)
+// ENUM(create_item,bump_item,delete_item,create_collection,delete_collection,rename_collection,pin_item,unpin_item,paste_item,move_item,restore_item,restore_collection,update_item_content,append_item_content,purge_item)
 type Action string
@@ -20,6 +20,24 @@ const (
 	ActionDeleteCollection Action = "delete_collection"
 	// ActionRenameCollection is a Action of type rename_collection.
 	ActionRenameCollection Action = "rename_collection"
+	// ActionPinItem is a Action of type pin_item.
+	ActionPinItem Action = "pin_item"
+	// ActionUnpinItem is a Action of type unpin_item.
+	ActionUnpinItem Action = "unpin_item"
+	// ActionPasteItem is a Action of type paste_item.
+	ActionPasteItem Action = "paste_item"
+	// ActionMoveItem is a Action of type move_item.
+	ActionMoveItem Action = "move_item"
+	// ActionRestoreItem is a Action of type restore_item.
+	ActionRestoreItem Action = "restore_item"
+	// ActionRestoreCollection is a Action of type restore_collection.
+	ActionRestoreCollection Action = "restore_collection"
+	// ActionUpdateItemContent is a Action of type update_item_content.
+	ActionUpdateItemContent Action = "update_item_content"
+	// ActionAppendItemContent is a Action of type append_item_content.
+	ActionAppendItemContent Action = "append_item_content"
+	// ActionPurgeItem is a Action of type purge_item.
+	ActionPurgeItem Action = "purge_item"
 )
 
 var ErrInvalidAction = errors.New("not a valid Action")
@@ -37,12 +55,21 @@ func (x Action) IsValid() bool {
 }
 
 var _ActionValue = map[string]Action{
-	"create_item":       ActionCreateItem,
-	"bump_item":         ActionBumpItem,
-	"delete_item":       ActionDeleteItem,
-	"create_collection": ActionCreateCollection,
-	"delete_collection": ActionDeleteCollection,
-	"rename_collection": ActionRenameCollection,
+	"create_item":         ActionCreateItem,
+	"bump_item":           ActionBumpItem,
+	"delete_item":         ActionDeleteItem,
+	"create_collection":   ActionCreateCollection,
+	"delete_collection":   ActionDeleteCollection,
+	"rename_collection":   ActionRenameCollection,
+	"pin_item":            ActionPinItem,
+	"unpin_item":          ActionUnpinItem,
+	"paste_item":          ActionPasteItem,
+	"move_item":           ActionMoveItem,
+	"restore_item":        ActionRestoreItem,
+	"restore_collection":  ActionRestoreCollection,
+	"update_item_content": ActionUpdateItemContent,
+	"append_item_content": ActionAppendItemContent,
+	"purge_item":          ActionPurgeItem,
 }
 
 // ParseAction attempts to convert a string to a Action.
@@ -9,6 +9,7 @@ import (
 	"github.com/oklog/ulid/v2"
 	"github.com/uptrace/bun"
 	"github.com/uptrace/bun/schema"
+	"go.trulyao.dev/bore/v2/database/models"
 	"go.trulyao.dev/bore/v2/pkg/errs"
 	"go.trulyao.dev/bore/v2/pkg/events/action"
 	"go.trulyao.dev/bore/v2/pkg/events/aggregate"
@@ -35,19 +36,25 @@ type Event struct {
 
 	AggregateType string `bun:"aggregate_type,notnull" json:"aggregate_type"` // The type of the aggregate, stored for easier querying.
 	AggregateID   string `bun:"aggregate_id,notnull"   json:"aggregate_id"`   // The ID of the aggregate, stored for easier querying.
+
+	// ContentHash is the checksum of the content the payload references (see payload.ContentReference), stored keyed so it is readable when the payload is encrypted.
+	ContentHash string `bun:"content_hash,nullzero" json:"content_hash,omitempty"`
+	// Content is stored in the contents table along with the event, unless the same content is already stored.
+	// It must be set for new content, events that reference existing content (e.g. to undo an edit) leave it nil.
+	Content *models.Content `bun:"-" json:"-"`
 }
 
 // New creates a new event with the given aggregate, type, and payload.
-func New(agg aggregate.Aggregate, payload payload.Payload) (*Event, error) {
+func New(agg aggregate.Aggregate, p payload.Payload) (*Event, error) {
 	if !agg.IsValid() {
 		return nil, ErrInvalidAggregate
 	}
 
-	if !payload.Type().IsValid() {
+	if !p.Type().IsValid() {
 		return nil, ErrInvalidEventType
 	}
 
-	data, err := json.Marshal(payload)
+	data, err := json.Marshal(p)
 	if err != nil {
 		return nil, err
 	}
@@ -56,9 +63,13 @@ func New(agg aggregate.Aggregate, payload payload.Payload) (*Event, error) {
 		ID:         ulid.Make().String(),
 		OccurredAt: time.Now(),
 		Aggregate:  agg,
-		Type:       payload.Type(),
+		Type:       p.Type(),
 		Payload:    data,
 	}
+	if reference, ok := p.(payload.ContentReference); ok {
+		e.ContentHash = reference.ContentChecksum()
+	}
+
 	if err := e.SetAggregate(agg); err != nil {
 		return nil, err
 	}
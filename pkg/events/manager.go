@@ -16,9 +16,12 @@ var (
 	ErrUnknownEventType = errs.New("unknown event type")
 	ErrNoEventsToAppend = errs.New("no events to append")
 	ErrNoEventApplied   = errs.New("no event applied")
+	ErrVersionMismatch  = errs.New("aggregate version mismatch")
 )
 
 // Manager handles event sourcing operations.
+// Payloads are encrypted with the cipher of the repository before they are stored, and decrypted as they are read back.
+// Contents are stored once in the contents table and referenced by the events, see Event.Content.
 type Manager struct {
 	db   *bun.DB
 	repo repository.Repository
@@ -28,6 +31,13 @@ type AppendOptions struct {
 	ExpectedVersion int64 // If equal or greater than zero, the current aggregate version must match this value.
 }
 
+type ApplyAllOptions struct {
+	// ExpectedVersions maps aggregate IDs to the version they must be at before their first event is appended.
+	ExpectedVersions map[string]int64
+	// Then runs in the same transaction after every projection has been applied, so it can record related state atomically.
+	Then func(ctx context.Context, tx bun.Tx) error
+}
+
 func DefaultAppendOptions() AppendOptions {
 	return AppendOptions{ExpectedVersion: -1}
 }
@@ -53,69 +63,146 @@ func (m *Manager) ApplyN(
 		ctx,
 		&sql.TxOptions{Isolation: 0, ReadOnly: false},
 		func(ctx context.Context, tx bun.Tx) error {
-			var currentVersion int64
-			err := tx.NewSelect().
-				Table("events").
-				ColumnExpr("IFNULL(MAX(aggregate_version), 0)").
-				Where("aggregate_type = ? AND aggregate_id = ?", agg.Type(), agg.ID()).
-				Scan(ctx, &currentVersion)
-			if err != nil {
-				return err
-			}
+			persisted, newVersion, err = m.appendAndProject(ctx, tx, agg, events, options)
+			return err
+		},
+	)
 
-			if options.ExpectedVersion >= 0 && currentVersion != options.ExpectedVersion {
-				return errs.New("aggregate version mismatch")
-			}
+	return persisted, newVersion, err
+}
 
-			timestamp := time.Now().UTC()
+// ApplyAll applies events that may target different aggregates in order, in a single transaction.
+func (m *Manager) ApplyAll(
+	ctx context.Context,
+	events []*Event,
+	options ApplyAllOptions,
+) ([]Event, error) {
+	if len(events) == 0 {
+		return nil, ErrNoEventsToAppend
+	}
 
-			rows := make([]*Event, 0, len(events))
-			for i := range events {
-				event := &events[i]
-				event.AggregateVersion = currentVersion + int64(i) + 1
+	var persisted []Event
+	err := m.db.RunInTx(
+		ctx,
+		&sql.TxOptions{Isolation: 0, ReadOnly: false},
+		func(ctx context.Context, tx bun.Tx) error {
+			checked := make(map[string]bool, len(options.ExpectedVersions))
+			for _, event := range events {
+				if !event.Aggregate.IsValid() {
+					return ErrInvalidAggregate
+				}
 
-				if err := event.SetAggregate(agg); err != nil {
-					return err
+				appendOptions := DefaultAppendOptions()
+				if version, ok := options.ExpectedVersions[event.Aggregate.ID()]; ok &&
+					!checked[event.Aggregate.ID()] {
+					appendOptions.ExpectedVersion = version
+					checked[event.Aggregate.ID()] = true
 				}
 
-				if event.OccurredAt.IsZero() {
-					event.OccurredAt = timestamp
+				saved, _, err := m.appendAndProject(
+					ctx,
+					tx,
+					event.Aggregate,
+					[]Event{*event},
+					appendOptions,
+				)
+				if err != nil {
+					return err
 				}
 
-				rows = append(rows, event)
+				persisted = append(persisted, saved...)
 			}
 
-			if _, err := tx.NewInsert().Model(&rows).Ignore().Exec(ctx); err != nil {
-				return err
+			if options.Then != nil {
+				return options.Then(ctx, tx)
 			}
 
-			// We need to re-fetch the events to get the auto-generated fields (like Sequence).
-			var savedEvents []Event
-			err = tx.NewSelect().
-				Model(&savedEvents).
-				Where("aggregate_type = ? AND aggregate_id = ?", agg.Type(), agg.ID()).
-				Where("aggregate_version > ?", currentVersion).
-				Order("aggregate_version ASC").
-				Scan(ctx)
-			if err != nil {
-				return err
-			}
+			return nil
+		},
+	)
 
-			for i := range savedEvents {
-				event := &savedEvents[i]
-				if err := m.applyProjection(ctx, tx, event); err != nil {
-					return err
-				}
+	return persisted, err
+}
+
+// appendAndProject appends events to the same aggregate and applies their projections within the given transaction.
+func (m *Manager) appendAndProject(
+	ctx context.Context,
+	tx bun.Tx,
+	agg aggregate.Aggregate,
+	events []Event,
+	options AppendOptions,
+) ([]Event, int64, error) {
+	currentVersion, err := m.version(ctx, tx, agg)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if options.ExpectedVersion >= 0 && currentVersion != options.ExpectedVersion {
+		return nil, 0, ErrVersionMismatch
+	}
+
+	timestamp := time.Now().UTC()
+
+	rows := make([]*Event, 0, len(events))
+	for i := range events {
+		event := &events[i]
+		event.AggregateVersion = currentVersion + int64(i) + 1
+
+		if err := event.SetAggregate(agg); err != nil {
+			return nil, 0, err
+		}
+
+		if event.OccurredAt.IsZero() {
+			event.OccurredAt = timestamp
+		}
+
+		row := *event
+		if row.Payload, err = m.repo.Cipher().SealJSON(event.Payload); err != nil {
+			return nil, 0, err
+		}
+
+		if event.ContentHash != "" {
+			row.ContentHash = m.repo.Cipher().Hash(event.ContentHash)
+		}
+
+		// The content has to be stored before the projection of the event that references it.
+		if event.Content != nil {
+			if err := m.repo.Contents().Put(ctx, tx, event.Content); err != nil {
+				return nil, 0, err
 			}
+		}
 
-			persisted = savedEvents
-			newVersion = currentVersion + int64(len(savedEvents))
+		rows = append(rows, &row)
+	}
 
-			return nil
-		},
-	)
+	if _, err := tx.NewInsert().Model(&rows).Ignore().Exec(ctx); err != nil {
+		return nil, 0, err
+	}
 
-	return persisted, newVersion, err
+	// We need to re-fetch the events to get the auto-generated fields (like Sequence).
+	var savedEvents []Event
+	err = tx.NewSelect().
+		Model(&savedEvents).
+		Where("aggregate_type = ? AND aggregate_id = ?", agg.Type(), agg.ID()).
+		Where("aggregate_version > ?", currentVersion).
+		Order("aggregate_version ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i := range savedEvents {
+		event := &savedEvents[i]
+		if err := m.open(event); err != nil {
+			return nil, 0, err
+		}
+
+		if err := m.applyProjection(ctx, tx, event); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return savedEvents, currentVersion + int64(len(savedEvents)), nil
 }
 
 // Apply appends a single event to the event store and applies the projection in a shared transaction.
@@ -141,6 +228,33 @@ func (m *Manager) Apply(
 	return events[0], newVersion, nil
 }
 
+// Version returns the current version of the aggregate, which is zero if it has no events.
+func (m *Manager) Version(ctx context.Context, agg aggregate.Aggregate) (int64, error) {
+	return m.version(ctx, m.db, agg)
+}
+
+func (m *Manager) version(ctx context.Context, db bun.IDB, agg aggregate.Aggregate) (int64, error) {
+	var version int64
+	err := db.NewSelect().
+		Table("events").
+		ColumnExpr("IFNULL(MAX(aggregate_version), 0)").
+		Where("aggregate_type = ? AND aggregate_id = ?", agg.Type(), agg.ID()).
+		Scan(ctx, &version)
+
+	return version, err
+}
+
+// open decrypts the payload of an event read from the store.
+func (m *Manager) open(event *Event) error {
+	data, err := m.repo.Cipher().OpenJSON(event.Payload)
+	if err != nil {
+		return errs.Wrap(err, "failed to decrypt event "+event.ID)
+	}
+
+	event.Payload = data
+	return nil
+}
+
 func (m *Manager) applyProjection(ctx context.Context, tx bun.Tx, event *Event) error {
 	if event == nil {
 		return errs.New("event cannot be nil")
@@ -152,8 +266,9 @@ func (m *Manager) applyProjection(ctx context.Context, tx bun.Tx, event *Event)
 	}
 
 	options := payload.ProjectionOptions{
-		Aggregate: event.Aggregate,
-		Sequence:  event.Sequence,
+		Aggregate:  event.Aggregate,
+		Sequence:   event.Sequence,
+		OccurredAt: event.OccurredAt,
 	}
 
 	return p.ApplyProjection(ctx, tx, m.repo, options)
@@ -0,0 +1,64 @@
+package payload
+
+import (
+	"context"
+
+	"github.com/uptrace/bun"
+	"go.trulyao.dev/bore/v2/database/repository"
+	"go.trulyao.dev/bore/v2/pkg/errs"
+	"go.trulyao.dev/bore/v2/pkg/events/action"
+)
+
+// AppendItemContent extends the content of an item, only the appended part is stored so the event stays small.
+type AppendItemContent struct {
+	Content   []byte `json:"content"`
+	Separator string `json:"separator,omitempty"` // Inserted between the existing content and the appended content.
+}
+
+// Suffix returns the bytes appended to the item's content.
+func (a *AppendItemContent) Suffix() []byte {
+	return append([]byte(a.Separator), a.Content...)
+}
+
+// ApplyProjection implements Payload.
+func (a *AppendItemContent) ApplyProjection(
+	ctx context.Context,
+	tx bun.Tx,
+	repo repository.Repository,
+	options ProjectionOptions,
+) error {
+	if !options.Aggregate.IsValid() {
+		return errs.New("invalid aggregate")
+	}
+
+	content, mergedInto, err := repo.Items().AppendContent(
+		ctx,
+		tx,
+		options.Aggregate.ID(),
+		a.Suffix(),
+		options.Sequence,
+		options.OccurredAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	// The combined content already existed in the collection, so the item was folded into that one.
+	if mergedInto != "" {
+		return repo.Search().Remove(ctx, tx, options.Aggregate.ID())
+	}
+
+	return repo.Search().Index(ctx, tx, options.Aggregate.ID(), content)
+}
+
+// Redact implements Redactable.
+func (a *AppendItemContent) Redact() {
+	a.Content, a.Separator = nil, ""
+}
+
+// Type implements Payload.
+func (a *AppendItemContent) Type() action.Action {
+	return action.ActionAppendItemContent
+}
+
+var _ Redactable = (*AppendItemContent)(nil)
@@ -2,13 +2,19 @@ package payload
 
 import (
 	"context"
+	"time"
 
 	"github.com/uptrace/bun"
 	"go.trulyao.dev/bore/v2/database/repository"
 	"go.trulyao.dev/bore/v2/pkg/events/action"
 )
 
-type BumpItem struct{}
+// BumpItem records that the content of an item was copied again.
+type BumpItem struct {
+	ExpiresAt time.Time `json:"expires_at,omitzero"`  // New expiry of the item if set, see CreateItem.ExpiresAt.
+	MaxPastes int       `json:"max_pastes,omitempty"` // New paste limit of the item if set, the pastes so far no longer count.
+	Sensitive bool      `json:"sensitive,omitempty"`  // Whether it was copied as sensitive, which marks the item as sensitive too.
+}
 
 // ApplyProjection implements Payload.
 func (b *BumpItem) ApplyProjection(
@@ -21,7 +27,25 @@ func (b *BumpItem) ApplyProjection(
 		return nil
 	}
 
-	return repo.Items().Bump(ctx, tx, options.Aggregate.ID(), options.Sequence)
+	if err := repo.Items().Bump(ctx, tx, options.Aggregate.ID(), options.Sequence); err != nil {
+		return err
+	}
+
+	if !b.ExpiresAt.IsZero() || b.MaxPastes > 0 {
+		if err := repo.Items().SetLimits(ctx, tx, options.Aggregate.ID(), b.ExpiresAt, b.MaxPastes); err != nil {
+			return err
+		}
+	}
+
+	if !b.Sensitive {
+		return nil
+	}
+
+	if err := repo.Items().MarkSensitive(ctx, tx, options.Aggregate.ID()); err != nil {
+		return err
+	}
+
+	return repo.Search().Remove(ctx, tx, options.Aggregate.ID())
 }
 
 // Type implements Payload.
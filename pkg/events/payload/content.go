@@ -0,0 +1,56 @@
+package payload
+
+import (
+	"context"
+
+	"github.com/uptrace/bun"
+	"go.trulyao.dev/bore/v2/database/models"
+	"go.trulyao.dev/bore/v2/database/repository"
+	"go.trulyao.dev/bore/v2/pkg/errs"
+	"go.trulyao.dev/bore/v2/pkg/lib"
+)
+
+// referencedContent returns the checksum of the content a payload references.
+// Payloads recorded before contents were deduplicated carry their content inline, it is stored first in that case.
+func referencedContent(
+	ctx context.Context,
+	tx bun.Tx,
+	repo repository.Repository,
+	checksum string,
+	inline []byte,
+) (string, error) {
+	if inline == nil {
+		if checksum == "" {
+			return "", errs.New("payload does not reference any content")
+		}
+
+		return checksum, nil
+	}
+
+	checksum = lib.ComputeChecksum(inline)
+	content := &models.Content{Hash: checksum, Content: inline, Size: int64(len(inline))}
+	return checksum, repo.Contents().Put(ctx, tx, content)
+}
+
+// indexContent adds the item to the search index with the content stored under the checksum.
+func indexContent(ctx context.Context, tx bun.Tx, repo repository.Repository, itemID, checksum string) error {
+	content, err := repo.Contents().Find(ctx, tx, checksum)
+	if err != nil {
+		return err
+	}
+
+	if content == nil {
+		return errs.New("content " + checksum + " not found")
+	}
+
+	return repo.Search().Index(ctx, tx, itemID, content.Content)
+}
+
+// inlineChecksum returns the checksum of inline content if a payload carries any, and the referenced checksum otherwise.
+func inlineChecksum(checksum string, inline []byte) string {
+	if inline != nil {
+		return lib.ComputeChecksum(inline)
+	}
+
+	return checksum
+}
@@ -3,20 +3,28 @@ package payload
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/uptrace/bun"
 	"go.trulyao.dev/bore/v2/database/models"
 	"go.trulyao.dev/bore/v2/database/repository"
 	"go.trulyao.dev/bore/v2/pkg/errs"
 	"go.trulyao.dev/bore/v2/pkg/events/action"
-	"go.trulyao.dev/bore/v2/pkg/lib"
 	"go.trulyao.dev/bore/v2/pkg/mimetype"
 )
 
+// CreateItem creates an item, its content is stored once in the contents table and only referenced by ContentHash, see events.Event.Content.
 type CreateItem struct {
-	Content      []byte            `json:"content"`
+	ContentHash string `json:"content_hash,omitempty"`
+	// Content is only set in events recorded before contents were deduplicated that were encrypted, so the migration could not rewrite them.
+	// Rekeying the database moves it into the contents table, see repository.EncryptionRepository.Rekey.
+	Content []byte `json:"content,omitempty"`
+
 	Mimetype     mimetype.MimeType `json:"mimetype"`
 	CollectionID string            `json:"collection_id"`
+	ExpiresAt    time.Time         `json:"expires_at,omitzero"`
+	MaxPastes    int               `json:"max_pastes,omitempty"`
+	Sensitive    bool              `json:"sensitive,omitempty"`
 }
 
 // ApplyProjection implements Payload.
@@ -30,18 +38,37 @@ func (c *CreateItem) ApplyProjection(
 		return errs.New("invalid aggregate")
 	}
 
-	hash := lib.ComputeChecksum(c.Content)
+	hash, err := referencedContent(ctx, tx, repo, c.ContentHash, c.Content)
+	if err != nil {
+		return err
+	}
 
 	row := models.Item{
 		ID:                    options.Aggregate.ID(),
-		Content:               c.Content,
 		Hash:                  hash,
 		Mimetype:              c.Mimetype.String(),
 		LastAppliedSequenceID: options.Sequence,
 		CollectionID:          sql.NullString{String: c.CollectionID, Valid: c.CollectionID != ""},
+		ExpiresAt:             bun.NullTime{Time: c.ExpiresAt.Truncate(time.Second)},
+		MaxPastes:             c.MaxPastes,
+		Sensitive:             c.Sensitive,
 	}
 
-	return repo.Items().Create(ctx, tx, &row)
+	if err := repo.Items().Create(ctx, tx, &row); err != nil {
+		return err
+	}
+
+	return indexContent(ctx, tx, repo, row.ID, hash)
+}
+
+// ContentChecksum implements ContentReference.
+func (c *CreateItem) ContentChecksum() string {
+	return inlineChecksum(c.ContentHash, c.Content)
+}
+
+// Redact implements Redactable, dropping the reference lets the content be deleted once nothing else references it.
+func (c *CreateItem) Redact() {
+	c.Content, c.ContentHash = nil, ""
 }
 
 // Type implements Payload.
@@ -49,4 +76,7 @@ func (c *CreateItem) Type() action.Action {
 	return action.ActionCreateItem
 }
 
-var _ Payload = (*CreateItem)(nil)
+var (
+	_ Redactable       = (*CreateItem)(nil)
+	_ ContentReference = (*CreateItem)(nil)
+)
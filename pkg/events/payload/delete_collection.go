@@ -22,6 +22,15 @@ func (d *DeleteCollection) ApplyProjection(
 		return errs.New("invalid aggregate")
 	}
 
+	if err := repo.Trash().TrashCollection(ctx, tx, options.Aggregate.ID(), options.Sequence, options.OccurredAt); err != nil {
+		return err
+	}
+
+	// Items are removed by the foreign key cascade, so their index entries have to go first.
+	if err := repo.Search().RemoveByCollection(ctx, tx, options.Aggregate.ID()); err != nil {
+		return err
+	}
+
 	return repo.Collections().DeleteById(ctx, tx, options.Aggregate.ID())
 }
 
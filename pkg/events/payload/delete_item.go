@@ -9,7 +9,10 @@ import (
 	"go.trulyao.dev/bore/v2/pkg/events/action"
 )
 
-type DeleteItem struct{}
+type DeleteItem struct {
+	// KeepPinned refuses the deletion with errs.ErrItemPinned if the item is pinned by the time the event is applied.
+	KeepPinned bool `json:"keep_pinned,omitempty"`
+}
 
 // ApplyProjection implements Payload.
 func (d *DeleteItem) ApplyProjection(
@@ -22,6 +25,18 @@ func (d *DeleteItem) ApplyProjection(
 		return errs.New("invalid aggregate")
 	}
 
+	if err := repo.Trash().TrashItem(ctx, tx, options.Aggregate.ID(), options.Sequence, options.OccurredAt); err != nil {
+		return err
+	}
+
+	if err := repo.Search().Remove(ctx, tx, options.Aggregate.ID()); err != nil {
+		return err
+	}
+
+	if d.KeepPinned {
+		return repo.Items().DeleteUnpinnedById(ctx, tx, options.Aggregate.ID())
+	}
+
 	return repo.Items().DeleteById(ctx, tx, options.Aggregate.ID())
 }
 
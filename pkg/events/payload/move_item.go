@@ -0,0 +1,45 @@
+package payload
+
+import (
+	"context"
+
+	"github.com/uptrace/bun"
+	"go.trulyao.dev/bore/v2/database/repository"
+	"go.trulyao.dev/bore/v2/pkg/errs"
+	"go.trulyao.dev/bore/v2/pkg/events/action"
+)
+
+type MoveItem struct {
+	CollectionID string `json:"collection_id"` // The target collection, empty to make the item uncategorized.
+}
+
+// ApplyProjection implements Payload.
+func (m *MoveItem) ApplyProjection(
+	ctx context.Context,
+	tx bun.Tx,
+	repo repository.Repository,
+	options ProjectionOptions,
+) error {
+	if !options.Aggregate.IsValid() {
+		return errs.New("invalid aggregate")
+	}
+
+	mergedInto, err := repo.Items().Move(ctx, tx, options.Aggregate.ID(), m.CollectionID)
+	if err != nil {
+		return err
+	}
+
+	// The item was folded into an existing item with the same content, so it no longer has its own index entry.
+	if mergedInto != "" {
+		return repo.Search().Remove(ctx, tx, options.Aggregate.ID())
+	}
+
+	return nil
+}
+
+// Type implements Payload.
+func (m *MoveItem) Type() action.Action {
+	return action.ActionMoveItem
+}
+
+var _ Payload = (*MoveItem)(nil)
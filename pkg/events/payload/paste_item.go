@@ -0,0 +1,34 @@
+package payload
+
+import (
+	"context"
+
+	"github.com/uptrace/bun"
+	"go.trulyao.dev/bore/v2/database/repository"
+	"go.trulyao.dev/bore/v2/pkg/errs"
+	"go.trulyao.dev/bore/v2/pkg/events/action"
+)
+
+// PasteItem records a paste of an item with a paste limit, so the limit survives replays.
+type PasteItem struct{}
+
+// ApplyProjection implements Payload.
+func (p *PasteItem) ApplyProjection(
+	ctx context.Context,
+	tx bun.Tx,
+	repo repository.Repository,
+	options ProjectionOptions,
+) error {
+	if !options.Aggregate.IsValid() {
+		return errs.New("invalid aggregate")
+	}
+
+	return repo.Items().IncrementPasteCount(ctx, tx, options.Aggregate.ID())
+}
+
+// Type implements Payload.
+func (p *PasteItem) Type() action.Action {
+	return action.ActionPasteItem
+}
+
+var _ Payload = (*PasteItem)(nil)
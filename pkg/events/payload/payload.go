@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/uptrace/bun"
 	"go.trulyao.dev/bore/v2/database/repository"
@@ -13,8 +14,9 @@ import (
 )
 
 type ProjectionOptions struct {
-	Sequence  int64               // The sequence number of the event being projected.
-	Aggregate aggregate.Aggregate // The aggregate associated with the event.
+	Sequence   int64               // The sequence number of the event being projected.
+	Aggregate  aggregate.Aggregate // The aggregate associated with the event.
+	OccurredAt time.Time           // The time the event occurred, projections must use this instead of the current time so replays are deterministic.
 }
 
 type Payload interface {
@@ -27,6 +29,20 @@ type Payload interface {
 	) error
 }
 
+// Redactable is implemented by payloads that carry item content.
+// Redact clears the content but keeps the rest of the payload, so the item's history can still be folded after it has been purged.
+type Redactable interface {
+	Payload
+	Redact()
+}
+
+// ContentReference is implemented by payloads that reference content in the contents table instead of carrying it.
+// ContentChecksum returns the checksum of that content, or an empty string if the payload has been redacted.
+type ContentReference interface {
+	Payload
+	ContentChecksum() string
+}
+
 type RawPayload interface {
 	[]byte | json.RawMessage
 }
@@ -54,6 +70,33 @@ func Decode[P RawPayload](data P, a action.Action) (Payload, error) {
 	case action.ActionRenameCollection:
 		target = new(RenameCollection)
 
+	case action.ActionPinItem:
+		target = new(PinItem)
+
+	case action.ActionUnpinItem:
+		target = new(UnpinItem)
+
+	case action.ActionPasteItem:
+		target = new(PasteItem)
+
+	case action.ActionMoveItem:
+		target = new(MoveItem)
+
+	case action.ActionRestoreItem:
+		target = new(RestoreItem)
+
+	case action.ActionRestoreCollection:
+		target = new(RestoreCollection)
+
+	case action.ActionUpdateItemContent:
+		target = new(UpdateItemContent)
+
+	case action.ActionAppendItemContent:
+		target = new(AppendItemContent)
+
+	case action.ActionPurgeItem:
+		target = new(PurgeItem)
+
 	default:
 		return nil, errs.New(fmt.Sprintf("unknown event action: %s", a))
 	}
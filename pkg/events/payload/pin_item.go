@@ -0,0 +1,33 @@
+package payload
+
+import (
+	"context"
+
+	"github.com/uptrace/bun"
+	"go.trulyao.dev/bore/v2/database/repository"
+	"go.trulyao.dev/bore/v2/pkg/errs"
+	"go.trulyao.dev/bore/v2/pkg/events/action"
+)
+
+type PinItem struct{}
+
+// ApplyProjection implements Payload.
+func (p *PinItem) ApplyProjection(
+	ctx context.Context,
+	tx bun.Tx,
+	repo repository.Repository,
+	options ProjectionOptions,
+) error {
+	if !options.Aggregate.IsValid() {
+		return errs.New("invalid aggregate")
+	}
+
+	return repo.Items().Pin(ctx, tx, options.Aggregate.ID(), options.OccurredAt)
+}
+
+// Type implements Payload.
+func (p *PinItem) Type() action.Action {
+	return action.ActionPinItem
+}
+
+var _ Payload = (*PinItem)(nil)
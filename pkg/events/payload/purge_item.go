@@ -0,0 +1,52 @@
+package payload
+
+import (
+	"context"
+
+	"github.com/uptrace/bun"
+	"go.trulyao.dev/bore/v2/database/repository"
+	"go.trulyao.dev/bore/v2/pkg/errs"
+	"go.trulyao.dev/bore/v2/pkg/events/action"
+)
+
+// PurgeItem permanently removes an item along with every copy of its content outside the event log: the row, the search index, the trash and the undo history.
+// The event manager redacts the content from the item's earlier events in the same transaction, see Redactable.
+type PurgeItem struct{}
+
+// ApplyProjection implements Payload.
+func (p *PurgeItem) ApplyProjection(
+	ctx context.Context,
+	tx bun.Tx,
+	repo repository.Repository,
+	options ProjectionOptions,
+) error {
+	if !options.Aggregate.IsValid() {
+		return errs.New("invalid aggregate")
+	}
+
+	itemID := options.Aggregate.ID()
+	if err := repo.Search().Remove(ctx, tx, itemID); err != nil {
+		return err
+	}
+
+	if err := repo.Search().Optimize(ctx, tx); err != nil {
+		return err
+	}
+
+	if err := repo.Trash().PurgeItem(ctx, tx, itemID); err != nil {
+		return err
+	}
+
+	if err := repo.Undo().RemoveForAggregate(ctx, tx, itemID); err != nil {
+		return err
+	}
+
+	return repo.Items().DeleteById(ctx, tx, itemID)
+}
+
+// Type implements Payload.
+func (p *PurgeItem) Type() action.Action {
+	return action.ActionPurgeItem
+}
+
+var _ Payload = (*PurgeItem)(nil)
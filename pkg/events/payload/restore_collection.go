@@ -0,0 +1,45 @@
+package payload
+
+import (
+	"context"
+
+	"github.com/uptrace/bun"
+	"go.trulyao.dev/bore/v2/database/repository"
+	"go.trulyao.dev/bore/v2/pkg/errs"
+	"go.trulyao.dev/bore/v2/pkg/events/action"
+)
+
+type RestoreCollection struct{}
+
+// ApplyProjection implements Payload.
+func (r *RestoreCollection) ApplyProjection(
+	ctx context.Context,
+	tx bun.Tx,
+	repo repository.Repository,
+	options ProjectionOptions,
+) error {
+	if !options.Aggregate.IsValid() {
+		return errs.New("invalid aggregate")
+	}
+
+	items, err := repo.Trash().
+		RestoreCollection(ctx, tx, options.Aggregate.ID())
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if err := repo.Search().Index(ctx, tx, item.ID, item.Content); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Type implements Payload.
+func (r *RestoreCollection) Type() action.Action {
+	return action.ActionRestoreCollection
+}
+
+var _ Payload = (*RestoreCollection)(nil)
@@ -0,0 +1,43 @@
+package payload
+
+import (
+	"context"
+
+	"github.com/uptrace/bun"
+	"go.trulyao.dev/bore/v2/database/repository"
+	"go.trulyao.dev/bore/v2/pkg/errs"
+	"go.trulyao.dev/bore/v2/pkg/events/action"
+)
+
+type RestoreItem struct{}
+
+// ApplyProjection implements Payload.
+func (r *RestoreItem) ApplyProjection(
+	ctx context.Context,
+	tx bun.Tx,
+	repo repository.Repository,
+	options ProjectionOptions,
+) error {
+	if !options.Aggregate.IsValid() {
+		return errs.New("invalid aggregate")
+	}
+
+	item, err := repo.Trash().RestoreItem(ctx, tx, options.Aggregate.ID())
+	if err != nil {
+		return err
+	}
+
+	// Nothing was restored, either the trash entry has expired or the content already exists again.
+	if item == nil {
+		return nil
+	}
+
+	return repo.Search().Index(ctx, tx, item.ID, item.Content)
+}
+
+// Type implements Payload.
+func (r *RestoreItem) Type() action.Action {
+	return action.ActionRestoreItem
+}
+
+var _ Payload = (*RestoreItem)(nil)
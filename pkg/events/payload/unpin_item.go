@@ -0,0 +1,33 @@
+package payload
+
+import (
+	"context"
+
+	"github.com/uptrace/bun"
+	"go.trulyao.dev/bore/v2/database/repository"
+	"go.trulyao.dev/bore/v2/pkg/errs"
+	"go.trulyao.dev/bore/v2/pkg/events/action"
+)
+
+type UnpinItem struct{}
+
+// ApplyProjection implements Payload.
+func (u *UnpinItem) ApplyProjection(
+	ctx context.Context,
+	tx bun.Tx,
+	repo repository.Repository,
+	options ProjectionOptions,
+) error {
+	if !options.Aggregate.IsValid() {
+		return errs.New("invalid aggregate")
+	}
+
+	return repo.Items().Unpin(ctx, tx, options.Aggregate.ID())
+}
+
+// Type implements Payload.
+func (u *UnpinItem) Type() action.Action {
+	return action.ActionUnpinItem
+}
+
+var _ Payload = (*UnpinItem)(nil)
@@ -0,0 +1,73 @@
+package payload
+
+import (
+	"context"
+
+	"github.com/uptrace/bun"
+	"go.trulyao.dev/bore/v2/database/repository"
+	"go.trulyao.dev/bore/v2/pkg/errs"
+	"go.trulyao.dev/bore/v2/pkg/events/action"
+)
+
+// UpdateItemContent replaces the content of an item, the new content is referenced the same way as in CreateItem.
+type UpdateItemContent struct {
+	ContentHash string `json:"content_hash,omitempty"`
+	// Content is only set in events recorded before contents were deduplicated, see CreateItem.Content.
+	Content []byte `json:"content,omitempty"`
+}
+
+// ApplyProjection implements Payload.
+func (u *UpdateItemContent) ApplyProjection(
+	ctx context.Context,
+	tx bun.Tx,
+	repo repository.Repository,
+	options ProjectionOptions,
+) error {
+	if !options.Aggregate.IsValid() {
+		return errs.New("invalid aggregate")
+	}
+
+	hash, err := referencedContent(ctx, tx, repo, u.ContentHash, u.Content)
+	if err != nil {
+		return err
+	}
+
+	mergedInto, err := repo.Items().UpdateContent(
+		ctx,
+		tx,
+		options.Aggregate.ID(),
+		hash,
+		options.Sequence,
+		options.OccurredAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	// The new content already existed in the collection, so the item was folded into that one.
+	if mergedInto != "" {
+		return repo.Search().Remove(ctx, tx, options.Aggregate.ID())
+	}
+
+	return indexContent(ctx, tx, repo, options.Aggregate.ID(), hash)
+}
+
+// ContentChecksum implements ContentReference.
+func (u *UpdateItemContent) ContentChecksum() string {
+	return inlineChecksum(u.ContentHash, u.Content)
+}
+
+// Redact implements Redactable.
+func (u *UpdateItemContent) Redact() {
+	u.Content, u.ContentHash = nil, ""
+}
+
+// Type implements Payload.
+func (u *UpdateItemContent) Type() action.Action {
+	return action.ActionUpdateItemContent
+}
+
+var (
+	_ Redactable       = (*UpdateItemContent)(nil)
+	_ ContentReference = (*UpdateItemContent)(nil)
+)
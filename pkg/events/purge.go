@@ -0,0 +1,95 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/uptrace/bun"
+	"go.trulyao.dev/bore/v2/pkg/events/aggregate"
+	"go.trulyao.dev/bore/v2/pkg/events/payload"
+)
+
+// PurgeItem applies a purge event to the item and redacts its content from every earlier event in its stream, in a single transaction.
+// Contents that are no longer referenced by anything are deleted along with it.
+// This is the only place events are rewritten, the stream itself (and what happened to the item) is kept.
+func (m *Manager) PurgeItem(ctx context.Context, itemID string) error {
+	agg, err := aggregate.WithID(aggregate.AggregateTypeItem, itemID)
+	if err != nil {
+		return err
+	}
+
+	event, err := New(agg, &payload.PurgeItem{})
+	if err != nil {
+		return err
+	}
+
+	return m.db.RunInTx(
+		ctx,
+		&sql.TxOptions{Isolation: 0, ReadOnly: false},
+		func(ctx context.Context, tx bun.Tx) error {
+			if _, _, err := m.appendAndProject(ctx, tx, agg, []Event{*event}, DefaultAppendOptions()); err != nil {
+				return err
+			}
+
+			if err := m.redactStream(ctx, tx, agg); err != nil {
+				return err
+			}
+
+			// Contents only referenced by the item's history are now unreferenced, any other item with the same content keeps it.
+			_, err := m.repo.Contents().DeleteUnreferenced(ctx, tx)
+			return err
+		},
+	)
+}
+
+// redactStream clears the content (or the reference to it) of every payload in the aggregate's stream that carries any.
+func (m *Manager) redactStream(ctx context.Context, tx bun.Tx, agg aggregate.Aggregate) error {
+	var stream []Event
+	err := tx.NewSelect().
+		Model(&stream).
+		Where("aggregate_type = ? AND aggregate_id = ?", agg.Type(), agg.ID()).
+		Scan(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := range stream {
+		event := &stream[i]
+		if err := m.open(event); err != nil {
+			return err
+		}
+
+		p, err := payload.Decode(event.Payload, event.Type)
+		if err != nil {
+			return err
+		}
+
+		redactable, ok := p.(payload.Redactable)
+		if !ok {
+			continue
+		}
+
+		redactable.Redact()
+		data, err := json.Marshal(redactable)
+		if err != nil {
+			return err
+		}
+
+		if data, err = m.repo.Cipher().SealJSON(data); err != nil {
+			return err
+		}
+
+		_, err = tx.NewUpdate().
+			Table("events").
+			Set("payload = ?", string(data)).
+			Set("content_hash = NULL").
+			Where("event_id = ?", event.ID).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
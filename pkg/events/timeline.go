@@ -0,0 +1,191 @@
+package events
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"go.trulyao.dev/bore/v2/database/repository"
+	"go.trulyao.dev/bore/v2/pkg/errs"
+	"go.trulyao.dev/bore/v2/pkg/events/action"
+	"go.trulyao.dev/bore/v2/pkg/events/aggregate"
+	"go.trulyao.dev/bore/v2/pkg/events/payload"
+	"go.trulyao.dev/bore/v2/pkg/mimetype"
+)
+
+// maxTimelineCandidates is the number of candidates listed when an ID prefix matches more than one stream.
+const maxTimelineCandidates = 5
+
+// ItemTimeline is the history of a single item, folded from its own event stream.
+// It only reflects events on the item itself, so an item removed by deleting its collection or by merging into a duplicate is not marked as deleted.
+type ItemTimeline struct {
+	ItemID    string    `json:"item_id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Content, Mimetype and CollectionID are the state of the item after the last event, content included even if it has been deleted.
+	Content      []byte            `json:"content"`
+	Mimetype     mimetype.MimeType `json:"mimetype"`
+	CollectionID string            `json:"collection_id"`
+
+	// BlobHash and Size are set instead of Content if the content is kept in the blob store.
+	BlobHash string `json:"blob_hash,omitempty"`
+	Size     int64  `json:"size,omitempty"`
+
+	// contentHash is the checksum of the content referenced last while folding, Content only holds what was appended to it until it is resolved.
+	contentHash string
+
+	// Ephemeral is set if the item was created with an expiry time or a paste limit.
+	Ephemeral bool `json:"ephemeral"`
+	// Sensitive is set if the item was copied as sensitive at any point.
+	Sensitive bool `json:"sensitive"`
+
+	CopiedAt  []time.Time `json:"copied_at"`  // Every time the same content was copied again.
+	CopyCount int         `json:"copy_count"` // The number of times the content was copied, including the first time.
+
+	Deleted   bool      `json:"deleted"`
+	DeletedAt time.Time `json:"deleted_at,omitzero"` // The time of the last delete, zero if the item has not been deleted.
+
+	// Purged is set if the item has been purged, its content is then gone from every event.
+	Purged bool `json:"purged"`
+
+	Entries []TimelineEntry `json:"entries"` // Every event in the stream, oldest first.
+}
+
+// TimelineEntry is a single event in an ItemTimeline.
+type TimelineEntry struct {
+	Version    int64         `json:"version"`
+	Action     action.Action `json:"action"`
+	OccurredAt time.Time     `json:"occurred_at"`
+}
+
+// ItemTimeline folds the event stream of an item, ordered by aggregate version, into its timeline.
+// It returns nil if the item has no events.
+func (m *Manager) ItemTimeline(ctx context.Context, itemID string) (*ItemTimeline, error) {
+	var stream []Event
+	err := m.db.NewSelect().
+		Model(&stream).
+		Where("aggregate_type = ? AND aggregate_id = ?", aggregate.AggregateTypeItem.String(), itemID).
+		Order("aggregate_version ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(stream) == 0 {
+		return nil, nil
+	}
+
+	timeline := &ItemTimeline{ItemID: itemID, Entries: make([]TimelineEntry, 0, len(stream))}
+	for _, event := range stream {
+		if err := m.open(&event); err != nil {
+			return nil, err
+		}
+
+		if err := timeline.apply(event); err != nil {
+			return nil, err
+		}
+	}
+
+	if timeline.contentHash == "" {
+		return timeline, nil
+	}
+
+	content, err := m.repo.Contents().Find(ctx, m.db, timeline.contentHash)
+	if err != nil {
+		return nil, err
+	}
+
+	if content != nil {
+		timeline.Content = append(slices.Clip(content.Content), timeline.Content...)
+		if content.BlobHash != "" {
+			timeline.BlobHash, timeline.Size = content.BlobHash, content.Size
+		}
+	}
+
+	return timeline, nil
+}
+
+// ResolveItemID returns the full ID of the only item whose event stream ID starts with the prefix, unlike the item repository this includes deleted items.
+// Full IDs and identifiers that cannot be a prefix are returned unchanged, an empty string is returned if nothing matches.
+func (m *Manager) ResolveItemID(ctx context.Context, prefix string) (string, error) {
+	prefix = strings.ToUpper(strings.TrimSpace(prefix))
+	if len(prefix) < repository.MinPrefixLength {
+		return prefix, nil
+	}
+
+	var ids []string
+	err := m.db.NewSelect().
+		Table("events").
+		Distinct().
+		Column("aggregate_id").
+		Where("aggregate_type = ?", aggregate.AggregateTypeItem.String()).
+		Where("aggregate_id LIKE ?", prefix+"%").
+		Order("aggregate_id ASC").
+		Limit(maxTimelineCandidates+1).
+		Scan(ctx, &ids)
+	if err != nil {
+		return "", err
+	}
+
+	switch len(ids) {
+	case 0:
+		return "", nil
+	case 1:
+		return ids[0], nil
+	}
+
+	candidates := ids[:min(len(ids), maxTimelineCandidates)]
+	if len(ids) > maxTimelineCandidates {
+		candidates = append(candidates, "...")
+	}
+
+	return "", &errs.AmbiguousIDError{Prefix: prefix, Candidates: candidates}
+}
+
+func (t *ItemTimeline) apply(event Event) error {
+	t.Entries = append(t.Entries, TimelineEntry{
+		Version:    event.AggregateVersion,
+		Action:     event.Type,
+		OccurredAt: event.OccurredAt,
+	})
+
+	p, err := payload.Decode(event.Payload, event.Type)
+	if err != nil {
+		return err
+	}
+
+	switch p := p.(type) {
+	case *payload.CreateItem:
+		// Undo and redo recreate items with the same ID, which is not another copy.
+		if t.CreatedAt.IsZero() {
+			t.CreatedAt = event.OccurredAt
+			t.CopyCount++
+		}
+
+		t.contentHash, t.Content = p.ContentHash, p.Content
+		t.Mimetype, t.CollectionID = p.Mimetype, p.CollectionID
+		t.Ephemeral = !p.ExpiresAt.IsZero() || p.MaxPastes > 0
+		t.Sensitive = t.Sensitive || p.Sensitive
+		t.Deleted, t.DeletedAt = false, time.Time{}
+	case *payload.BumpItem:
+		t.CopiedAt = append(t.CopiedAt, event.OccurredAt)
+		t.CopyCount++
+		t.Sensitive = t.Sensitive || p.Sensitive
+	case *payload.UpdateItemContent:
+		t.contentHash, t.Content = p.ContentHash, p.Content
+	case *payload.AppendItemContent:
+		t.Content = append(t.Content, p.Suffix()...)
+	case *payload.MoveItem:
+		t.CollectionID = p.CollectionID
+	case *payload.DeleteItem:
+		t.Deleted, t.DeletedAt = true, event.OccurredAt
+	case *payload.RestoreItem:
+		t.Deleted, t.DeletedAt = false, time.Time{}
+	case *payload.PurgeItem:
+		t.contentHash, t.Content, t.Purged = "", nil, true
+		t.Deleted, t.DeletedAt = true, event.OccurredAt
+	}
+
+	return nil
+}
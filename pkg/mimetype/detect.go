@@ -0,0 +1,92 @@
+package mimetype
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// MimeTypeApplicationOctetStream is used for binary content that could not be identified.
+const MimeTypeApplicationOctetStream MimeType = "application/octet-stream"
+
+// sniffLength is how much of the content the text heuristics look at.
+const sniffLength = 4096
+
+var (
+	htmlStart     = regexp.MustCompile(`(?i)^<(!doctype html|html|head|body|div|span|p|a|table|ul|ol|h[1-6]|li|tr|td|b|i|em|strong|code|pre|script|style|link|meta|section|article|main|nav|form|img|br)[\s>/]`)
+	markdownLine  = regexp.MustCompile(`^(#{1,6} |[-*+] |\d+\. |> |` + "```" + `|\|.*\|$)`)
+	markdownBlock = regexp.MustCompile(`(?m)^(#{1,6} |` + "```" + `)`)
+	markdownLink  = regexp.MustCompile(`\[[^\]\n]+\]\([^)\s]+\)`)
+)
+
+// Detect guesses the media type of the content.
+// Binary formats are recognised by their signature first, then the extension of filename (if any) is used, and text is classified by its structure.
+// Text that is not recognised is text/plain, and binary content that is not recognised is application/octet-stream.
+func Detect(content []byte, filename string) MimeType {
+	sniffed := http.DetectContentType(content)
+	if !strings.HasPrefix(sniffed, "text/") && sniffed != string(MimeTypeApplicationOctetStream) {
+		if detected, err := ParseMimeType(sniffed); err == nil {
+			return detected
+		}
+	}
+
+	if extension := filepath.Ext(filename); extension != "" {
+		if detected, err := ParseMimeType(mime.TypeByExtension(extension)); err == nil {
+			return detected
+		}
+	}
+
+	if !utf8.Valid(content) {
+		return MimeTypeApplicationOctetStream
+	}
+
+	return detectText(bytes.TrimSpace(content))
+}
+
+func detectText(text []byte) MimeType {
+	if len(text) == 0 {
+		return MimeTypeTextPlain
+	}
+
+	if (text[0] == '{' || text[0] == '[') && json.Valid(text) {
+		return MimeTypeApplicationJson
+	}
+
+	head := text[:min(len(text), sniffLength)]
+	if head[0] == '<' {
+		lower := bytes.ToLower(head)
+		switch {
+		case htmlStart.Match(head):
+			return "text/html"
+		case bytes.HasPrefix(lower, []byte("<svg")),
+			bytes.HasPrefix(lower, []byte("<?xml")) && bytes.Contains(lower, []byte("<svg")):
+			return "image/svg+xml"
+		case bytes.HasPrefix(lower, []byte("<?xml")), bytes.HasSuffix(text, []byte(">")):
+			return "application/xml"
+		}
+	}
+
+	if isMarkdown(head) {
+		return "text/markdown"
+	}
+
+	return MimeTypeTextPlain
+}
+
+// isMarkdown reports whether the text has at least two lines of markdown syntax, one of them a heading, a code fence or a link.
+// A single list item or quote is too common in plain text to count on its own.
+func isMarkdown(text []byte) bool {
+	lines := 0
+	for line := range bytes.Lines(text) {
+		if markdownLine.Match(bytes.TrimSpace(line)) {
+			lines++
+		}
+	}
+
+	return lines >= 2 && (markdownBlock.Match(text) || markdownLink.Match(text))
+}
@@ -1,6 +1,135 @@
 package mimetype
 
-//go:generate go tool github.com/abice/go-enum --marshal
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"regexp"
+	"strings"
+)
 
-// ENUM(application/json,text/plain)
+// MimeType is a media type in its canonical form: lowercase type, subtype and parameter names, with the parameters sorted, e.g. "text/plain; charset=utf-8".
 type MimeType string
+
+const (
+	// MimeTypeApplicationJson is a MimeType of type application/json.
+	MimeTypeApplicationJson MimeType = "application/json"
+	// MimeTypeTextPlain is a MimeType of type text/plain.
+	MimeTypeTextPlain MimeType = "text/plain"
+)
+
+var ErrInvalidMimeType = errors.New("not a valid MimeType")
+
+// restrictedName matches type, subtype and parameter names as defined by RFC 6838 section 4.2.
+var restrictedName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9!#$&^_.+-]{0,126}$`)
+
+// MediaType is a parsed media type.
+type MediaType struct {
+	Type    string
+	Subtype string
+
+	// Params holds the parameters (e.g. charset), keyed by their lowercase name.
+	Params map[string]string
+}
+
+// Parse parses a media type like "text/plain; charset=utf-8".
+// The type, subtype and parameter names must follow the RFC 6838 syntax, parameter values can be tokens or quoted strings.
+func Parse(value string) (MediaType, error) {
+	essence, params, err := mime.ParseMediaType(strings.TrimSpace(value))
+	if err != nil {
+		return MediaType{}, fmt.Errorf("%s is %w: %w", value, ErrInvalidMimeType, err)
+	}
+
+	mediaType, subtype, ok := strings.Cut(essence, "/")
+	if !ok || !restrictedName.MatchString(mediaType) || !restrictedName.MatchString(subtype) {
+		return MediaType{}, fmt.Errorf("%s is %w: expected type/subtype", value, ErrInvalidMimeType)
+	}
+
+	for name := range params {
+		if !restrictedName.MatchString(name) {
+			return MediaType{}, fmt.Errorf("%s is %w: invalid parameter name %q", value, ErrInvalidMimeType, name)
+		}
+	}
+
+	// Charset names are case-insensitive (RFC 2046 section 4.1.2), so "UTF-8" and "utf-8" are stored the same way.
+	if charset, ok := params["charset"]; ok {
+		params["charset"] = strings.ToLower(charset)
+	}
+
+	if len(params) == 0 {
+		params = nil
+	}
+
+	return MediaType{Type: mediaType, Subtype: subtype, Params: params}, nil
+}
+
+// Essence returns the type and subtype without the parameters, e.g. "text/plain".
+func (m MediaType) Essence() string {
+	return m.Type + "/" + m.Subtype
+}
+
+// String returns the canonical form of the media type.
+func (m MediaType) String() string {
+	return mime.FormatMediaType(m.Essence(), m.Params)
+}
+
+// MimeType returns the canonical form of the media type as a MimeType.
+func (m MediaType) MimeType() MimeType {
+	return MimeType(m.String())
+}
+
+// ParseMimeType validates a media type and returns it in its canonical form.
+func ParseMimeType(name string) (MimeType, error) {
+	mediaType, err := Parse(name)
+	if err != nil {
+		return MimeType(""), err
+	}
+
+	return mediaType.MimeType(), nil
+}
+
+// String implements the Stringer interface.
+func (x MimeType) String() string {
+	return string(x)
+}
+
+// IsValid reports whether the value is a valid media type.
+func (x MimeType) IsValid() bool {
+	_, err := Parse(string(x))
+	return err == nil
+}
+
+// MediaType parses the value, see Parse.
+func (x MimeType) MediaType() (MediaType, error) {
+	return Parse(string(x))
+}
+
+// Essence returns the type and subtype without the parameters, or the value as-is if it is not a valid media type.
+func (x MimeType) Essence() string {
+	mediaType, err := x.MediaType()
+	if err != nil {
+		return string(x)
+	}
+
+	return mediaType.Essence()
+}
+
+// MarshalText implements the text marshaller method.
+func (x MimeType) MarshalText() ([]byte, error) {
+	return []byte(string(x)), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *MimeType) UnmarshalText(text []byte) error {
+	tmp, err := ParseMimeType(string(text))
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
+// AppendText implements the text appender method, appending the media type to b as-is.
+func (x *MimeType) AppendText(b []byte) ([]byte, error) {
+	return append(b, x.String()...), nil
+}
@@ -0,0 +1,87 @@
+package mimetype_test
+
+import (
+	"errors"
+	"testing"
+
+	"go.trulyao.dev/bore/v2/pkg/mimetype"
+)
+
+func Test_ParseMimeType(t *testing.T) {
+	tests := []struct {
+		input string
+		want  mimetype.MimeType
+	}{
+		{input: "text/plain", want: mimetype.MimeTypeTextPlain},
+		{input: " Image/PNG ", want: "image/png"},
+		{input: `Text/Plain; Charset="UTF-8"`, want: "text/plain; charset=utf-8"},
+		{input: "application/vnd.api+json", want: "application/vnd.api+json"},
+		{input: "multipart/form-data; boundary=x; a=\"b c\"", want: `multipart/form-data; a="b c"; boundary=x`},
+	}
+
+	for _, tt := range tests {
+		got, err := mimetype.ParseMimeType(tt.input)
+		if err != nil {
+			t.Errorf("%q: expected no error, got %v", tt.input, err)
+			continue
+		}
+
+		if got != tt.want {
+			t.Errorf("%q: expected %q, got %q", tt.input, tt.want, got)
+		}
+	}
+}
+
+func Test_ParseMimeType_Invalid(t *testing.T) {
+	for _, input := range []string{"", "text", "text/", "/plain", "text/pl@in", "-text/plain", "text/plain; =x"} {
+		if _, err := mimetype.ParseMimeType(input); !errors.Is(err, mimetype.ErrInvalidMimeType) {
+			t.Errorf("%q: expected ErrInvalidMimeType, got %v", input, err)
+		}
+	}
+}
+
+func Test_MediaType(t *testing.T) {
+	mediaType, err := mimetype.MimeType("text/markdown; charset=utf-8; variant=GFM").MediaType()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if mediaType.Type != "text" || mediaType.Subtype != "markdown" || mediaType.Essence() != "text/markdown" {
+		t.Errorf("unexpected media type %+v", mediaType)
+	}
+
+	if mediaType.Params["variant"] != "GFM" {
+		t.Errorf("expected parameter values other than charset to keep their case, got %q", mediaType.Params["variant"])
+	}
+}
+
+func Test_Detect(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		filename string
+		want     mimetype.MimeType
+	}{
+		{name: "png", content: "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR", want: "image/png"},
+		{name: "png with misleading extension", content: "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR", filename: "notes.txt", want: "image/png"},
+		{name: "pdf", content: "%PDF-1.7\n", want: "application/pdf"},
+		{name: "json object", content: "  {\"a\": [1, 2]}\n", want: mimetype.MimeTypeApplicationJson},
+		{name: "json scalar", content: "42", want: mimetype.MimeTypeTextPlain},
+		{name: "html", content: "<!DOCTYPE html><html><body>hi</body></html>", want: "text/html"},
+		{name: "xml", content: "<?xml version=\"1.0\"?><root/>", want: "application/xml"},
+		{name: "svg", content: "<svg xmlns=\"http://www.w3.org/2000/svg\"></svg>", want: "image/svg+xml"},
+		{name: "markdown", content: "# Title\n\nSee [the docs](https://example.com)\n\n- one\n- two", want: "text/markdown"},
+		{name: "list is not markdown", content: "- milk\n- eggs", want: mimetype.MimeTypeTextPlain},
+		{name: "extension", content: "body { color: red }", filename: "style.css", want: "text/css; charset=utf-8"},
+		{name: "plain text", content: "hello world", want: mimetype.MimeTypeTextPlain},
+		{name: "binary", content: "\x00\x01\x02\xff", want: mimetype.MimeTypeApplicationOctetStream},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mimetype.Detect([]byte(tt.content), tt.filename); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
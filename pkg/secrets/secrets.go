@@ -0,0 +1,156 @@
+package secrets
+
+import (
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"go.trulyao.dev/bore/v2/pkg/errs"
+)
+
+const (
+	// HighEntropyRule is the name reported for long random-looking strings that no other rule matched.
+	HighEntropyRule = "high-entropy-string"
+
+	// maxScanSize caps how much of the content is scanned, secrets are rarely buried deeper than this.
+	maxScanSize = 1 << 20
+
+	// Strings shorter than minEntropyLength are not checked for entropy, and minEntropy is in bits per character.
+	// Hex digests (at most 4 bits per character) stay below the threshold, base64 keys and tokens usually do not.
+	minEntropyLength = 32
+	minEntropy       = 4.3
+)
+
+// Rule flags content matching its pattern as a secret.
+type Rule struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// Detector checks content against a set of rules, followed by an entropy check if enabled.
+type Detector struct {
+	rules   []Rule
+	entropy bool
+}
+
+var builtinRules = []Rule{
+	{Name: "private-key", Pattern: regexp.MustCompile(`-----BEGIN[A-Z ]* PRIVATE KEY( BLOCK)?-----`)},
+	{Name: "aws-access-key", Pattern: regexp.MustCompile(`\b(AKIA|ASIA|ABIA|ACCA)[A-Z0-9]{16}\b`)},
+	{Name: "github-token", Pattern: regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{Name: "gitlab-token", Pattern: regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}\b`)},
+	{Name: "slack-token", Pattern: regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}\b`)},
+	{Name: "stripe-key", Pattern: regexp.MustCompile(`\b(sk|rk)_live_[A-Za-z0-9]{16,}\b`)},
+	{Name: "google-api-key", Pattern: regexp.MustCompile(`\bAIza[A-Za-z0-9_-]{35}\b`)},
+	{Name: "openai-key", Pattern: regexp.MustCompile(`\bsk-(proj-)?[A-Za-z0-9_-]{32,}\b`)},
+	{Name: "jwt", Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`)},
+	{
+		Name: "env-secret",
+		Pattern: regexp.MustCompile(
+			`(?m)^\s*(export\s+)?[A-Za-z0-9_]*(SECRET|TOKEN|PASSWORD|PASSWD|API_KEY|APIKEY|PRIVATE_KEY|ACCESS_KEY)[A-Za-z0-9_]*\s*=\s*['"]?[^\s'"]{6,}`,
+		),
+	},
+}
+
+// Builtin returns the built-in rules, which cover private key blocks, common token formats and `.env` assignments of secret-looking variables.
+func Builtin() []Rule {
+	return append([]Rule(nil), builtinRules...)
+}
+
+// ParseRules compiles user rules, keyed by rule name with a regular expression as the value.
+func ParseRules(patterns map[string]string) ([]Rule, error) {
+	rules := make([]Rule, 0, len(patterns))
+	for name, pattern := range patterns {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, errs.New("secret detection rules must have a name")
+		}
+
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, errs.Wrap(err, "invalid pattern for secret detection rule "+name)
+		}
+
+		rules = append(rules, Rule{Name: name, Pattern: compiled})
+	}
+
+	// Map order is random, sort so the same rule is reported every time when several match.
+	slices.SortFunc(rules, func(a, b Rule) int { return strings.Compare(a.Name, b.Name) })
+	return rules, nil
+}
+
+// New creates a detector with the given rules, the entropy check runs after them if entropy is set.
+func New(rules []Rule, entropy bool) *Detector {
+	return &Detector{rules: rules, entropy: entropy}
+}
+
+// Detect returns the name of the first rule the content matches.
+// Content that is not valid UTF-8 is never reported, binary data is random-looking by nature.
+func (d *Detector) Detect(content []byte) (string, bool) {
+	if d == nil || !utf8.Valid(content) {
+		return "", false
+	}
+
+	if len(content) > maxScanSize {
+		content = content[:maxScanSize]
+	}
+
+	for _, rule := range d.rules {
+		if rule.Pattern.Match(content) {
+			return rule.Name, true
+		}
+	}
+
+	if d.entropy && hasHighEntropyString(string(content)) {
+		return HighEntropyRule, true
+	}
+
+	return "", false
+}
+
+// hasHighEntropyString reports whether the text contains a long token made of key-like characters that looks random.
+func hasHighEntropyString(text string) bool {
+	tokens := strings.FieldsFunc(text, func(r rune) bool {
+		return !isTokenRune(r)
+	})
+
+	for _, token := range tokens {
+		if len(token) >= minEntropyLength && hasLettersAndDigits(token) && shannonEntropy(token) >= minEntropy {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isTokenRune(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') ||
+		r == '+' || r == '/' || r == '=' || r == '_' || r == '-'
+}
+
+func hasLettersAndDigits(token string) bool {
+	return strings.ContainsAny(token, "0123456789") &&
+		strings.ContainsFunc(token, func(r rune) bool { return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') })
+}
+
+// shannonEntropy returns the entropy of the token in bits per character.
+func shannonEntropy(token string) float64 {
+	var counts [256]int
+	for i := 0; i < len(token); i++ {
+		counts[token[i]]++
+	}
+
+	var entropy float64
+	length := float64(len(token))
+	for _, count := range counts {
+		if count == 0 {
+			continue
+		}
+
+		p := float64(count) / length
+		entropy -= p * math.Log2(p)
+	}
+
+	return entropy
+}
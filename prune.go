@@ -0,0 +1,198 @@
+package bore
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"go.trulyao.dev/bore/v2/database/models"
+	"go.trulyao.dev/bore/v2/database/repository"
+	"go.trulyao.dev/bore/v2/pkg/errs"
+)
+
+type PruneReason string
+
+const (
+	PruneReasonExpired  PruneReason = "expired"
+	PruneReasonMaxAge   PruneReason = "max_age"
+	PruneReasonMaxItems PruneReason = "max_items"
+	PruneReasonMaxBytes PruneReason = "max_bytes"
+)
+
+type (
+	PruneOptions struct {
+		DryRun bool // Whether to only report what would be removed without removing anything.
+	}
+
+	PrunedItem struct {
+		ItemID         string
+		CollectionID   string
+		CollectionName string
+		Size           int64
+		Reason         PruneReason
+	}
+
+	PruneResult struct {
+		Items      []PrunedItem
+		FreedBytes int64
+	}
+)
+
+// Prune removes expired items and applies the configured retention policies to every collection, including uncategorized items.
+// Items are removed with regular delete events so the event log stays consistent with the projections.
+// Trash entries older than the trash retention are removed as well, along with contents and blobs nothing references anymore, unless it is a dry run.
+func (i *clipboardNamespace) Prune(ctx context.Context, options PruneOptions) (PruneResult, error) {
+	//nolint:exhaustruct
+	collections, err := i.repository.Collections().FindAll(ctx, repository.FindAllOptions{})
+	if err != nil {
+		return PruneResult{}, errs.New("failed to list collections").WithError(err)
+	}
+
+	result, err := i.sweepExpired(ctx, options.DryRun)
+	if err != nil {
+		return result, err
+	}
+
+	// The zero collection stands in for uncategorized items.
+	targets := []*models.Collection{{}}
+	for _, collection := range collections {
+		targets = append(targets, &collection.Collection)
+	}
+
+	for _, collection := range targets {
+		if err := i.pruneCollection(ctx, collection, options, &result); err != nil {
+			return result, err
+		}
+	}
+
+	if !options.DryRun {
+		if _, err := i.Trash().Expire(ctx); err != nil {
+			return result, err
+		}
+
+		if _, err := i.collectContents(ctx, time.Now().Add(-blobGracePeriod)); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// pruneAfterCopy prunes the collection an item was just copied into and expires old trash entries.
+// Failures are logged instead of returned since the copy itself has already succeeded.
+func (i *clipboardNamespace) pruneAfterCopy(ctx context.Context, collectionID string) {
+	i.Trash().expireTrash(ctx)
+
+	collection := new(models.Collection)
+	if collectionID != "" {
+		var err error
+		if collection, err = i.repository.Collections().FindById(ctx, collectionID); err != nil ||
+			collection == nil {
+			return
+		}
+	}
+
+	var result PruneResult
+	if err := i.pruneCollection(ctx, collection, PruneOptions{DryRun: false}, &result); err != nil {
+		slog.Warn("failed to prune collection after copy", slog.String("error", err.Error()))
+	}
+}
+
+func (i *clipboardNamespace) pruneCollection(
+	ctx context.Context,
+	collection *models.Collection,
+	options PruneOptions,
+	result *PruneResult,
+) error {
+	policy := i.config.RetentionFor(collection.ID, collection.Name)
+	if policy.IsZero() {
+		return nil
+	}
+
+	summaries, err := i.repository.Items().FindUnpinnedSummaries(ctx, collection.ID)
+	if err != nil {
+		return errs.New("failed to list items to prune").WithError(err)
+	}
+
+	var (
+		kept        int
+		keptBytes   int64
+		limitReason PruneReason
+		now         = time.Now()
+	)
+
+	// In a dry run, expired items are still in the table and must not be reported twice.
+	alreadyPruned := make(map[string]bool, len(result.Items))
+	for _, item := range result.Items {
+		alreadyPruned[item.ItemID] = true
+	}
+
+	for _, summary := range summaries {
+		if alreadyPruned[summary.ID] {
+			continue
+		}
+
+		var reason PruneReason
+
+		// Summaries are ordered most recent first, so once a count or size limit is crossed every older item goes too.
+		switch {
+		case limitReason != "":
+			reason = limitReason
+		case policy.MaxAge > 0 && now.Sub(summary.CopiedAt) > policy.MaxAge:
+			reason = PruneReasonMaxAge
+		case policy.MaxItems > 0 && kept >= policy.MaxItems:
+			reason, limitReason = PruneReasonMaxItems, PruneReasonMaxItems
+		case policy.MaxBytes > 0 && keptBytes+summary.Size > policy.MaxBytes:
+			reason, limitReason = PruneReasonMaxBytes, PruneReasonMaxBytes
+		default:
+			kept++
+			keptBytes += summary.Size
+			continue
+		}
+
+		if !options.DryRun {
+			if err := i.deleteItem(ctx, summary.ID); err != nil {
+				return errs.New("failed to prune item " + summary.ID).WithError(err)
+			}
+		}
+
+		result.Items = append(result.Items, PrunedItem{
+			ItemID:         summary.ID,
+			CollectionID:   collection.ID,
+			CollectionName: collection.Name,
+			Size:           summary.Size,
+			Reason:         reason,
+		})
+		result.FreedBytes += summary.Size
+	}
+
+	return nil
+}
+
+// sweepExpired deletes every unpinned item that has expired by time or paste count.
+func (i *clipboardNamespace) sweepExpired(ctx context.Context, dryRun bool) (PruneResult, error) {
+	summaries, err := i.repository.Items().FindExpired(ctx, time.Now())
+	if err != nil {
+		return PruneResult{}, errs.New("failed to list expired items").WithError(err)
+	}
+
+	var result PruneResult
+	for _, summary := range summaries {
+		if !dryRun {
+			if err := i.deleteItem(ctx, summary.ID); err != nil {
+				return result, errs.New("failed to delete expired item " + summary.ID).WithError(err)
+			}
+		}
+
+		result.Items = append(result.Items, PrunedItem{
+			ItemID:         summary.ID,
+			CollectionID:   summary.CollectionID.String,
+			CollectionName: "",
+			Size:           summary.Size,
+			Reason:         PruneReasonExpired,
+		})
+		result.FreedBytes += summary.Size
+	}
+
+	return result, nil
+}
@@ -0,0 +1,122 @@
+package bore_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"go.trulyao.dev/bore/v2"
+)
+
+// backdate moves every event of the item the given duration into the past.
+func backdate(t *testing.T, b *bore.Bore, itemID string, by time.Duration) {
+	t.Helper()
+
+	db, err := b.DB()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	occurredAt := time.Now().Add(-by).UTC().Format("2006-01-02T15:04:05.000Z")
+	if _, err := db.ExecContext(context.Background(), "UPDATE events SET occurred_at = ? WHERE aggregate_id = ?", occurredAt, itemID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+// prunedIDs returns the IDs of the pruned items.
+func prunedIDs(result bore.PruneResult) []string {
+	ids := make([]string, 0, len(result.Items))
+	for _, item := range result.Items {
+		ids = append(ids, item.ItemID)
+	}
+
+	return ids
+}
+
+func Test_PruneMaxItemsAfterCopy(t *testing.T) {
+	ctx := context.Background()
+	b := newBoreWithConfig(t, &bore.Config{Retention: bore.RetentionPolicy{MaxItems: 2}})
+
+	pinnedID := copyItem(t, b, "pinned", bore.SetClipboardOptions{})
+	if err := b.Clipboard().Pin(ctx, pinnedID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var ids []string
+	for _, content := range []string{"one", "two", "three"} {
+		ids = append(ids, copyItem(t, b, content, bore.SetClipboardOptions{}))
+	}
+
+	if item := findItem(t, b, ids[0]); item != nil {
+		t.Errorf("expected the oldest item to be pruned after the copy, got %+v", item)
+	}
+
+	// Pinned items are neither removed nor counted against the limit.
+	for _, id := range []string{pinnedID, ids[1], ids[2]} {
+		if item := findItem(t, b, id); item == nil {
+			t.Errorf("expected %s to be kept", id)
+		}
+	}
+}
+
+func Test_PruneMaxAge(t *testing.T) {
+	ctx := context.Background()
+	config := &bore.Config{}
+	b := newBoreWithConfig(t, config)
+
+	oldID := copyItem(t, b, "old", bore.SetClipboardOptions{})
+	touchedID := copyItem(t, b, "old but pasted and pinned", bore.SetClipboardOptions{})
+	recopiedID := copyItem(t, b, "old but copied again", bore.SetClipboardOptions{})
+	freshID := copyItem(t, b, "fresh", bore.SetClipboardOptions{})
+
+	for _, id := range []string{oldID, touchedID, recopiedID} {
+		backdate(t, b, id, 48*time.Hour)
+	}
+
+	// Pinning and unpinning updates the item, but it was not copied again.
+	if err := b.Clipboard().Pin(ctx, touchedID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := b.Clipboard().Unpin(ctx, touchedID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	copyItem(t, b, "old but copied again", bore.SetClipboardOptions{})
+
+	config.Retention = bore.RetentionPolicy{MaxAge: 24 * time.Hour}
+
+	result, err := b.Clipboard().Prune(ctx, bore.PruneOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	got := prunedIDs(result)
+	slices.Sort(got)
+	want := []string{oldID, touchedID}
+	slices.Sort(want)
+
+	if !slices.Equal(got, want) {
+		t.Errorf("expected %v to be pruned, got %v", want, got)
+	}
+
+	for _, item := range result.Items {
+		if item.Reason != bore.PruneReasonMaxAge {
+			t.Errorf("expected %s to be pruned for its age, got %s", item.ItemID, item.Reason)
+		}
+	}
+
+	if item := findItem(t, b, oldID); item == nil {
+		t.Error("expected a dry run to keep the item")
+	}
+
+	if _, err := b.Clipboard().Prune(ctx, bore.PruneOptions{DryRun: false}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for id, wantKept := range map[string]bool{oldID: false, touchedID: false, recopiedID: true, freshID: true} {
+		if kept := findItem(t, b, id) != nil; kept != wantKept {
+			t.Errorf("expected %s to be kept: %v, got %v", id, wantKept, kept)
+		}
+	}
+}
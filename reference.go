@@ -0,0 +1,35 @@
+package bore
+
+import (
+	"strconv"
+	"strings"
+
+	"go.trulyao.dev/bore/v2/pkg/errs"
+)
+
+// ItemReference is a relative reference to the Nth most recent item, written as `@N` or `collection@N`.
+// `@0` is the latest item, `@1` the one before it and so on.
+type ItemReference struct {
+	Collection string // The collection ID or name, the caller's collection is used if empty.
+	Offset     int
+}
+
+// IsItemReference reports whether the identifier is a relative reference rather than an item ID.
+func IsItemReference(identifier string) bool {
+	return strings.Contains(identifier, "@")
+}
+
+// ParseItemReference parses a relative item reference like `@2` or `work@2`.
+func ParseItemReference(identifier string) (ItemReference, error) {
+	collection, rawOffset, found := strings.Cut(strings.TrimSpace(identifier), "@")
+	if !found {
+		return ItemReference{}, errs.ErrInvalidItemReference
+	}
+
+	offset, err := strconv.Atoi(rawOffset)
+	if err != nil || offset < 0 {
+		return ItemReference{}, errs.ErrInvalidItemReference
+	}
+
+	return ItemReference{Collection: strings.TrimSpace(collection), Offset: offset}, nil
+}
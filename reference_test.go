@@ -0,0 +1,38 @@
+package bore_test
+
+import (
+	"testing"
+
+	"go.trulyao.dev/bore/v2"
+)
+
+func Test_ParseItemReference(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    bore.ItemReference
+		wantErr bool
+	}{
+		{input: "@0", want: bore.ItemReference{Collection: "", Offset: 0}},
+		{input: "@3", want: bore.ItemReference{Collection: "", Offset: 3}},
+		{input: "work@2", want: bore.ItemReference{Collection: "work", Offset: 2}},
+		{input: "@", wantErr: true},
+		{input: "@-1", wantErr: true},
+		{input: "work@two", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := bore.ParseItemReference(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%q: expected an error, got %+v", tt.input, got)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%q: expected no error, got %v", tt.input, err)
+		} else if got != tt.want {
+			t.Errorf("%q: expected %+v, got %+v", tt.input, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package bore
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"go.trulyao.dev/bore/v2/database/models"
+	"go.trulyao.dev/bore/v2/database/repository"
+	"go.trulyao.dev/bore/v2/pkg/errs"
+)
+
+// regexSnippetContext is the number of bytes shown on either side of a regex match in its snippet.
+const regexSnippetContext = 40
+
+type SearchOptions struct {
+	Query          string // Search terms, or a regular expression if Regex is set.
+	CollectionID   string // Optional collection ID to scope the search to.
+	Regex          bool   // Whether to treat the query as a regular expression instead of full-text search terms.
+	HighlightStart string // Inserted before every match in the snippet.
+	HighlightEnd   string // Inserted after every match in the snippet.
+	Limit          int    // Maximum number of results, zero for no limit.
+}
+
+// Search finds clipboard items matching the query.
+// Full-text results are ranked by relevance, regex results are ordered by recency since there is no meaningful ranking for an exact pattern.
+func (i *clipboardNamespace) Search(
+	ctx context.Context,
+	options SearchOptions,
+) (models.ItemSearchResults, error) {
+	if strings.TrimSpace(options.Query) == "" {
+		return nil, errs.New("search query cannot be empty")
+	}
+
+	if options.Regex {
+		return i.searchRegex(ctx, options)
+	}
+
+	results, err := i.repository.Search().Search(ctx, repository.SearchOptions{
+		Query:          options.Query,
+		CollectionID:   options.CollectionID,
+		HighlightStart: options.HighlightStart,
+		HighlightEnd:   options.HighlightEnd,
+		Limit:          options.Limit,
+	})
+	if err != nil {
+		return nil, errs.New("failed to search items").WithError(err)
+	}
+
+	for _, result := range results {
+		result.Snippet = collapseWhitespace(result.Snippet)
+	}
+
+	return results, nil
+}
+
+func (i *clipboardNamespace) searchRegex(
+	ctx context.Context,
+	options SearchOptions,
+) (models.ItemSearchResults, error) {
+	pattern, err := regexp.Compile(options.Query)
+	if err != nil {
+		return nil, errs.New("invalid regular expression").WithError(err)
+	}
+
+	//nolint:exhaustruct
+	items, err := i.repository.Items().FindAll(ctx, repository.FindItemsOptions{
+		CollectionID: options.CollectionID,
+	})
+	if err != nil {
+		return nil, errs.New("failed to search items").WithError(err)
+	}
+
+	var results models.ItemSearchResults
+	for _, item := range items {
+		loc := pattern.FindIndex(item.Content)
+		if loc == nil {
+			continue
+		}
+
+		results = append(results, &models.ItemSearchResult{
+			Item:    *item,
+			Snippet: regexSnippet(item.Content, loc, options.HighlightStart, options.HighlightEnd),
+			Rank:    0,
+		})
+
+		if options.Limit > 0 && len(results) >= options.Limit {
+			break
+		}
+	}
+
+	return results, nil
+}
+
+// regexSnippet returns the content surrounding the match at loc with the match itself highlighted.
+func regexSnippet(content []byte, loc []int, highlightStart, highlightEnd string) string {
+	start := max(loc[0]-regexSnippetContext, 0)
+	end := min(loc[1]+regexSnippetContext, len(content))
+
+	// Avoid cutting multi-byte characters in half at either end of the snippet.
+	for start > 0 && !utf8.RuneStart(content[start]) {
+		start--
+	}
+	for end < len(content) && !utf8.RuneStart(content[end]) {
+		end++
+	}
+
+	var sb strings.Builder
+	if start > 0 {
+		sb.WriteString("…")
+	}
+	sb.Write(content[start:loc[0]])
+	sb.WriteString(highlightStart)
+	sb.Write(content[loc[0]:loc[1]])
+	sb.WriteString(highlightEnd)
+	sb.Write(content[loc[1]:end])
+	if end < len(content) {
+		sb.WriteString("…")
+	}
+
+	return collapseWhitespace(sb.String())
+}
+
+// collapseWhitespace replaces runs of whitespace (including newlines) with a single space so snippets fit on one line.
+func collapseWhitespace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,115 @@
+package bore_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"go.trulyao.dev/bore/v2"
+)
+
+// search returns the content of the items matching the query.
+func search(t *testing.T, b *bore.Bore, options bore.SearchOptions) []string {
+	t.Helper()
+
+	results, err := b.Clipboard().Search(context.Background(), options)
+	if err != nil {
+		t.Fatalf("%q: expected no error, got %v", options.Query, err)
+	}
+
+	contents := make([]string, 0, len(results))
+	for _, result := range results {
+		contents = append(contents, string(result.Content))
+	}
+
+	return contents
+}
+
+// indexedItems returns the number of entries in the full-text index.
+func indexedItems(t *testing.T, b *bore.Bore) int {
+	t.Helper()
+
+	db, err := b.DB()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var count int
+	if err := db.QueryRowContext(context.Background(), "SELECT COUNT(*) FROM items_fts").Scan(&count); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	return count
+}
+
+func Test_Search(t *testing.T) {
+	b := newBore(t)
+	copyItem(t, b, "the quick brown fox", bore.SetClipboardOptions{})
+	copyItem(t, b, "a lazy dog", bore.SetClipboardOptions{})
+	copyItem(t, b, "ssh foo-bar.example.com", bore.SetClipboardOptions{})
+
+	tests := []struct {
+		query string
+		regex bool
+		want  []string
+	}{
+		{query: "quick", want: []string{"the quick brown fox"}},
+		{query: "fox quick", want: []string{"the quick brown fox"}},
+		{query: "bro", want: []string{"the quick brown fox"}},
+		{query: "foo-bar.example", want: []string{"ssh foo-bar.example.com"}},
+		{query: "quick dog", want: []string{}},
+		{query: `l[a-z]+y`, regex: true, want: []string{"a lazy dog"}},
+	}
+
+	for _, test := range tests {
+		got := search(t, b, bore.SearchOptions{Query: test.query, Regex: test.regex})
+		if strings.Join(got, "|") != strings.Join(test.want, "|") {
+			t.Errorf("%q: expected %q, got %q", test.query, test.want, got)
+		}
+	}
+
+	results, err := b.Clipboard().Search(context.Background(), bore.SearchOptions{
+		Query:          "quick",
+		HighlightStart: "[",
+		HighlightEnd:   "]",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(results) != 1 || results[0].Snippet != "the [quick] brown fox" {
+		t.Errorf("expected the match to be highlighted, got %+v", results)
+	}
+}
+
+func Test_SearchIndexFollowsDeletes(t *testing.T) {
+	ctx := context.Background()
+	b := newBore(t)
+	collectionID := createCollection(t, b, "work")
+	itemID := copyItem(t, b, "pasted once", bore.SetClipboardOptions{})
+	copyItem(t, b, "work notes", bore.SetClipboardOptions{CollectionID: collectionID})
+
+	if _, err := b.Get(ctx, bore.GetClipboardOptions{ItemID: itemID, DeleteAfterPaste: true}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if got := search(t, b, bore.SearchOptions{Query: "pasted"}); len(got) != 0 {
+		t.Errorf("expected the deleted item to not be found, got %q", got)
+	}
+
+	if count := indexedItems(t, b); count != 1 {
+		t.Errorf("expected the deleted item to be removed from the index, got %d entries", count)
+	}
+
+	if got := search(t, b, bore.SearchOptions{Query: "notes", CollectionID: collectionID}); len(got) != 1 {
+		t.Errorf("expected the item to be found in its collection, got %q", got)
+	}
+
+	if err := b.Collections().Delete(ctx, collectionID, bore.DeleteCollectionOptions{}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if count := indexedItems(t, b); count != 0 {
+		t.Errorf("expected the collection's items to be removed from the index, got %d entries", count)
+	}
+}
@@ -0,0 +1,115 @@
+package bore_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"go.trulyao.dev/bore/v2"
+	"go.trulyao.dev/bore/v2/pkg/events/action"
+)
+
+func Test_Timeline(t *testing.T) {
+	ctx := context.Background()
+	b := newBore(t)
+	collectionID := createCollection(t, b, "notes")
+	itemID := copyItem(t, b, "draft", bore.SetClipboardOptions{})
+	copyItem(t, b, "other", bore.SetClipboardOptions{})
+
+	if id := copyItem(t, b, "draft", bore.SetClipboardOptions{}); id != itemID {
+		t.Fatalf("expected %s to be bumped, got %s", itemID, id)
+	}
+
+	if _, err := b.Clipboard().Update(ctx, itemID, []byte("final"), bore.UpdateItemOptions{ExpectedVersion: -1}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if _, err := b.Clipboard().Move(ctx, itemID, collectionID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := b.Clipboard().Delete(ctx, itemID, bore.DeleteItemOptions{}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	timeline, err := b.Clipboard().Timeline(ctx, itemID[:len(itemID)-2], "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if timeline.ItemID != itemID {
+		t.Fatalf("expected the timeline of %s, got %s", itemID, timeline.ItemID)
+	}
+
+	if string(timeline.Content) != "final" || timeline.CollectionID != collectionID {
+		t.Errorf("expected the last content and collection, got %q in %q", timeline.Content, timeline.CollectionID)
+	}
+
+	if timeline.CopyCount != 2 || len(timeline.CopiedAt) != 1 {
+		t.Errorf("expected 2 copies with 1 bump, got %d copies and %d bumps", timeline.CopyCount, len(timeline.CopiedAt))
+	}
+
+	if !timeline.Deleted || timeline.DeletedAt.IsZero() {
+		t.Errorf("expected the item to be marked as deleted, got %+v", timeline)
+	}
+
+	actions := make([]action.Action, 0, len(timeline.Entries))
+	for _, entry := range timeline.Entries {
+		actions = append(actions, entry.Action)
+	}
+
+	expected := []action.Action{
+		action.ActionCreateItem,
+		action.ActionBumpItem,
+		action.ActionUpdateItemContent,
+		action.ActionMoveItem,
+		action.ActionDeleteItem,
+	}
+	if !slices.Equal(actions, expected) {
+		t.Errorf("expected entries %v, got %v", expected, actions)
+	}
+}
+
+func Test_PasteDeleted(t *testing.T) {
+	ctx := context.Background()
+	b := newBore(t)
+	collectionID := createCollection(t, b, "notes")
+	itemID := copyItem(t, b, "gone", bore.SetClipboardOptions{CollectionID: collectionID})
+	ephemeralID := copyItem(t, b, "secret", bore.SetClipboardOptions{MaxPastes: 5})
+
+	for _, id := range []string{itemID, ephemeralID} {
+		if err := b.Clipboard().Delete(ctx, id, bore.DeleteItemOptions{}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		options  bore.GetClipboardOptions
+		expected string
+	}{
+		{"by id", bore.GetClipboardOptions{ItemID: itemID}, "gone"},
+		{"by prefix", bore.GetClipboardOptions{ItemID: itemID[:len(itemID)-2]}, "gone"},
+		{"in collection", bore.GetClipboardOptions{ItemID: itemID, CollectionID: collectionID}, "gone"},
+		{"in another collection", bore.GetClipboardOptions{ItemID: itemID, CollectionID: "none", SkipCollectionCheck: true}, ""},
+		{"ephemeral", bore.GetClipboardOptions{ItemID: ephemeralID}, ""},
+		{"reference", bore.GetClipboardOptions{ItemID: "@1"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := b.Get(ctx, tt.options)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if string(result.Content) != tt.expected {
+				t.Fatalf("expected %q to be pasted, got %q", tt.expected, result.Content)
+			}
+
+			if tt.expected != "" && (!result.Deleted || result.Item == nil || result.Item.ID != itemID) {
+				t.Errorf("expected %s to be recovered, got %+v", itemID, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package bore
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"go.trulyao.dev/bore/v2/database/models"
+	"go.trulyao.dev/bore/v2/pkg/errs"
+	"go.trulyao.dev/bore/v2/pkg/events"
+	"go.trulyao.dev/bore/v2/pkg/events/aggregate"
+	"go.trulyao.dev/bore/v2/pkg/events/payload"
+)
+
+type trashNamespace struct {
+	*Bore
+}
+
+type RestoreResult struct {
+	ID   string
+	Type aggregate.AggregateType
+	Name string // The name of the restored collection, empty for items.
+}
+
+// List returns the trashed items and collections, most recently deleted first.
+// Items that were deleted together with their collection are only included in the collection's item count.
+func (t *trashNamespace) List(ctx context.Context) (models.TrashEntries, error) {
+	entries, err := t.repository.Trash().FindAll(ctx)
+	if err != nil {
+		return nil, errs.New("failed to list trash").WithError(err)
+	}
+
+	return entries, nil
+}
+
+// Restore moves a trashed item or collection back, given its ID or a unique ID prefix.
+// A collection is restored with the items that were deleted along with it.
+func (t *trashNamespace) Restore(ctx context.Context, identifier string) (RestoreResult, error) {
+	id, err := t.repository.Trash().ResolveID(ctx, identifier)
+	if err != nil {
+		return RestoreResult{}, errs.Wrap(err, "failed to resolve trash entry")
+	}
+
+	entry, err := t.repository.Trash().FindLatest(ctx, id)
+	if err != nil {
+		return RestoreResult{}, errs.New("failed to find trash entry").WithError(err)
+	}
+
+	if id == "" || entry == nil {
+		return RestoreResult{}, errs.ErrTrashEntryNotFound
+	}
+
+	var (
+		redo, undo  payload.Payload
+		description string
+	)
+
+	switch entry.AggregateType {
+	case aggregate.AggregateTypeCollection.String():
+		redo, undo = &payload.RestoreCollection{}, &payload.DeleteCollection{}
+		description = "restore collection " + entry.Name.String
+	default:
+		if err := t.checkRestorable(ctx, entry); err != nil {
+			return RestoreResult{}, err
+		}
+
+		redo, undo = &payload.RestoreItem{}, &payload.DeleteItem{}
+		description = "restore item " + entry.AggregateID
+	}
+
+	aggregateType := aggregate.AggregateType(entry.AggregateType)
+
+	restoreEvent, err := newEvent(aggregateType, entry.AggregateID, redo)
+	if err != nil {
+		return RestoreResult{}, err
+	}
+
+	deleteEvent, err := newEvent(aggregateType, entry.AggregateID, undo)
+	if err != nil {
+		return RestoreResult{}, err
+	}
+
+	err = t.applyUndoable(ctx, description, []*events.Event{restoreEvent}, []*events.Event{deleteEvent})
+	if err != nil {
+		return RestoreResult{}, errs.New("failed to apply restore event").WithError(err)
+	}
+
+	return RestoreResult{ID: entry.AggregateID, Type: aggregateType, Name: entry.Name.String}, nil
+}
+
+// checkRestorable returns an error if the trashed item would be dropped on restore because its content already exists again.
+func (t *trashNamespace) checkRestorable(ctx context.Context, entry *models.TrashEntry) error {
+	// Items whose collection has been deleted are restored as uncategorized.
+	collectionID := entry.CollectionID.String
+	if collectionID != "" {
+		collection, err := t.repository.Collections().FindById(ctx, collectionID)
+		if err != nil {
+			return errs.New("failed to find collection").WithError(err)
+		}
+
+		if collection == nil {
+			collectionID = ""
+		}
+	}
+
+	existing, err := t.repository.Items().FindByHash(ctx, entry.Hash.String, collectionID)
+	if err != nil {
+		return errs.New("failed to check for duplicate items").WithError(err)
+	}
+
+	if existing != nil && existing.CollectionID.String == collectionID {
+		return errs.New("the same content already exists as item " + existing.ID)
+	}
+
+	return nil
+}
+
+// Empty permanently removes everything in the trash and returns the number of removed entries.
+func (t *trashNamespace) Empty(ctx context.Context) (int64, error) {
+	removed, err := t.repository.Trash().DeleteBefore(ctx, time.Time{})
+	if err != nil {
+		return 0, errs.New("failed to empty trash").WithError(err)
+	}
+
+	return removed, nil
+}
+
+// Expire permanently removes trash entries older than the configured trash retention.
+func (t *trashNamespace) Expire(ctx context.Context) (int64, error) {
+	retention := t.config.TrashRetentionPeriod()
+	if retention <= 0 {
+		return 0, nil
+	}
+
+	removed, err := t.repository.Trash().DeleteBefore(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return 0, errs.New("failed to expire trash").WithError(err)
+	}
+
+	return removed, nil
+}
+
+// expireTrash is Expire for callers that should not fail because of it.
+func (t *trashNamespace) expireTrash(ctx context.Context) {
+	if _, err := t.Expire(ctx); err != nil {
+		slog.Warn("failed to expire trash", slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,115 @@
+package bore_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.trulyao.dev/bore/v2"
+	"go.trulyao.dev/bore/v2/pkg/errs"
+)
+
+func Test_RestoreItem(t *testing.T) {
+	ctx := context.Background()
+	b := newBore(t)
+	itemID := copyItem(t, b, "hello", bore.SetClipboardOptions{})
+
+	if err := b.Clipboard().Pin(ctx, itemID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	deleted := findItem(t, b, itemID)
+	if err := b.Clipboard().Delete(ctx, itemID, bore.DeleteItemOptions{}); !errors.Is(err, errs.ErrItemPinned) {
+		t.Fatalf("expected ErrItemPinned, got %v", err)
+	}
+
+	if err := b.Clipboard().Delete(ctx, itemID, bore.DeleteItemOptions{Force: true}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	entries, err := b.Trash().List(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(entries) != 1 || entries[0].AggregateID != itemID || string(entries[0].Content) != "hello" {
+		t.Fatalf("expected the item to be in the trash, got %+v", entries)
+	}
+
+	result, err := b.Trash().Restore(ctx, itemID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if result.ID != itemID {
+		t.Errorf("expected %s to be restored, got %+v", itemID, result)
+	}
+
+	restored := findItem(t, b, itemID)
+	if restored == nil || restored.Hash != deleted.Hash || string(restored.Content) != "hello" || !restored.IsPinned() {
+		t.Errorf("expected the item to be restored with its hash and pin, got %+v", restored)
+	}
+
+	if entries, err := b.Trash().List(ctx); err != nil || len(entries) != 0 {
+		t.Errorf("expected the trash to be empty, got %+v (%v)", entries, err)
+	}
+
+	if _, err := b.Trash().Restore(ctx, itemID); !errors.Is(err, errs.ErrTrashEntryNotFound) {
+		t.Errorf("expected ErrTrashEntryNotFound, got %v", err)
+	}
+}
+
+// An item is not restored over an item that has been created with the same content in the meantime.
+func Test_RestoreItemDuplicate(t *testing.T) {
+	ctx := context.Background()
+	b := newBore(t)
+	itemID := copyItem(t, b, "hello", bore.SetClipboardOptions{})
+
+	if err := b.Clipboard().Delete(ctx, itemID, bore.DeleteItemOptions{}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	copyItem(t, b, "hello", bore.SetClipboardOptions{})
+
+	if _, err := b.Trash().Restore(ctx, itemID); err == nil {
+		t.Error("expected the restore to be refused")
+	}
+}
+
+func Test_RestoreCollection(t *testing.T) {
+	ctx := context.Background()
+	b := newBore(t)
+	collectionID := createCollection(t, b, "work")
+	itemID := copyItem(t, b, "hello", bore.SetClipboardOptions{CollectionID: collectionID})
+
+	if err := b.Collections().Delete(ctx, "work", bore.DeleteCollectionOptions{}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if item := findItem(t, b, itemID); item != nil {
+		t.Fatalf("expected the item to be deleted with its collection, got %+v", item)
+	}
+
+	// Items deleted with their collection are only listed as part of it.
+	entries, err := b.Trash().List(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(entries) != 1 || entries[0].AggregateID != collectionID {
+		t.Fatalf("expected only the collection to be listed, got %+v", entries)
+	}
+
+	result, err := b.Trash().Restore(ctx, collectionID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if result.Name != "work" {
+		t.Errorf("expected the work collection to be restored, got %+v", result)
+	}
+
+	if item := findItem(t, b, itemID); item == nil || item.CollectionID.String != collectionID {
+		t.Errorf("expected the item to be restored into its collection, got %+v", item)
+	}
+}
@@ -0,0 +1,193 @@
+package bore
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+	"go.trulyao.dev/bore/v2/database/models"
+	"go.trulyao.dev/bore/v2/pkg/errs"
+	"go.trulyao.dev/bore/v2/pkg/events"
+	"go.trulyao.dev/bore/v2/pkg/events/action"
+	"go.trulyao.dev/bore/v2/pkg/events/aggregate"
+	"go.trulyao.dev/bore/v2/pkg/events/payload"
+	"go.trulyao.dev/bore/v2/pkg/mimetype"
+)
+
+type UndoResult struct {
+	Description string // A short description of the operation that was undone or redone, e.g. "delete item 01J...".
+}
+
+// Undo reverts the most recent user operation by appending its compensating events.
+// Nothing is removed from the event log, so an undo can itself be redone.
+func (b *Bore) Undo(ctx context.Context) (UndoResult, error) {
+	entry, err := b.repository.Undo().FindLastDone(ctx)
+	if err != nil {
+		return UndoResult{}, errs.New("failed to read undo stack").WithError(err)
+	}
+
+	if entry == nil {
+		return UndoResult{}, errs.ErrNothingToUndo
+	}
+
+	undoneAt := bun.NullTime{Time: time.Now().UTC()}
+	if err := b.replayUndoEvents(ctx, entry.UndoEvents, entry.ID, undoneAt); err != nil {
+		return UndoResult{}, errs.New("failed to undo " + entry.Description).WithError(err)
+	}
+
+	return UndoResult{Description: entry.Description}, nil
+}
+
+// Redo applies the most recently undone operation again.
+func (b *Bore) Redo(ctx context.Context) (UndoResult, error) {
+	entry, err := b.repository.Undo().FindLastUndone(ctx)
+	if err != nil {
+		return UndoResult{}, errs.New("failed to read redo stack").WithError(err)
+	}
+
+	if entry == nil {
+		return UndoResult{}, errs.ErrNothingToRedo
+	}
+
+	if err := b.replayUndoEvents(ctx, entry.RedoEvents, entry.ID, bun.NullTime{}); err != nil {
+		return UndoResult{}, errs.New("failed to redo " + entry.Description).WithError(err)
+	}
+
+	return UndoResult{Description: entry.Description}, nil
+}
+
+// applyUndoable applies the events of a user operation and records it on the undo stack in the same transaction.
+// The redo events are the events of the operation itself, the undo events must revert them.
+func (b *Bore) applyUndoable(
+	ctx context.Context,
+	description string,
+	redo []*events.Event,
+	undo []*events.Event,
+) error {
+	return b.applyUndoableExpecting(ctx, description, redo, undo, nil)
+}
+
+// applyUndoableExpecting is applyUndoable with optimistic concurrency, see events.ApplyAllOptions.ExpectedVersions.
+func (b *Bore) applyUndoableExpecting(
+	ctx context.Context,
+	description string,
+	redo []*events.Event,
+	undo []*events.Event,
+	expectedVersions map[string]int64,
+) error {
+	entry := &models.UndoEntry{
+		Description: description,
+		UndoEvents:  toUndoEvents(undo),
+		RedoEvents:  toUndoEvents(redo),
+		UndoneAt:    bun.NullTime{},
+		CreatedAt:   time.Time{},
+	}
+
+	_, err := b.manager.ApplyAll(ctx, redo, events.ApplyAllOptions{
+		ExpectedVersions: expectedVersions,
+		Then: func(ctx context.Context, tx bun.Tx) error {
+			return b.repository.Undo().Push(ctx, tx, entry)
+		},
+	})
+
+	return err
+}
+
+// replayUndoEvents applies stored events as fresh events and moves the entry between the undo and redo stacks.
+func (b *Bore) replayUndoEvents(
+	ctx context.Context,
+	stored []models.UndoEvent,
+	entryID int64,
+	undoneAt bun.NullTime,
+) error {
+	pending := make([]*events.Event, 0, len(stored))
+	for _, s := range stored {
+		agg, err := aggregate.FromRaw(s.AggregateType, s.AggregateID)
+		if err != nil {
+			return err
+		}
+
+		p, err := payload.Decode(s.Payload, action.Action(s.Type))
+		if err != nil {
+			return err
+		}
+
+		e, err := events.New(agg, p)
+		if err != nil {
+			return err
+		}
+
+		pending = append(pending, e)
+	}
+
+	_, err := b.manager.ApplyAll(ctx, pending, events.ApplyAllOptions{
+		ExpectedVersions: nil,
+		Then: func(ctx context.Context, tx bun.Tx) error {
+			return b.repository.Undo().SetUndone(ctx, tx, entryID, undoneAt)
+		},
+	})
+
+	return err
+}
+
+// recreateItemEvents returns the events that bring back a deleted item with the same ID, content and pin.
+// The content is only referenced by its hash, the undo entry keeps it from being deleted.
+func recreateItemEvents(item *models.Item) ([]*events.Event, error) {
+	create, err := newEvent(aggregate.AggregateTypeItem, item.ID, &payload.CreateItem{
+		ContentHash:  item.Hash,
+		Content:      nil,
+		Mimetype:     mimetype.MimeType(item.Mimetype),
+		CollectionID: item.CollectionID.String,
+		ExpiresAt:    item.ExpiresAt.Time,
+		MaxPastes:    item.MaxPastes,
+		Sensitive:    item.Sensitive,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !item.IsPinned() {
+		return []*events.Event{create}, nil
+	}
+
+	pin, err := newEvent(aggregate.AggregateTypeItem, item.ID, &payload.PinItem{})
+	if err != nil {
+		return nil, err
+	}
+
+	return []*events.Event{create, pin}, nil
+}
+
+func newEvent(
+	aggregateType aggregate.AggregateType,
+	id string,
+	p payload.Payload,
+) (*events.Event, error) {
+	agg, err := aggregate.WithID(aggregateType, id)
+	if err != nil {
+		return nil, errs.New("failed to create aggregate for " + p.Type().String() + " event").
+			WithError(err)
+	}
+
+	e, err := events.New(agg, p)
+	if err != nil {
+		return nil, errs.New("failed to create " + p.Type().String() + " event").WithError(err)
+	}
+
+	return e, nil
+}
+
+func toUndoEvents(pending []*events.Event) []models.UndoEvent {
+	stored := make([]models.UndoEvent, 0, len(pending))
+	for _, e := range pending {
+		stored = append(stored, models.UndoEvent{
+			AggregateType: e.AggregateType,
+			AggregateID:   e.AggregateID,
+			Type:          e.Type.String(),
+			Payload:       e.Payload,
+			ContentHash:   e.ContentHash,
+		})
+	}
+
+	return stored
+}
@@ -0,0 +1,119 @@
+package bore_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.trulyao.dev/bore/v2"
+	"go.trulyao.dev/bore/v2/pkg/errs"
+)
+
+func Test_UndoRedoCopy(t *testing.T) {
+	ctx := context.Background()
+	b := newBore(t)
+
+	if _, err := b.Undo(ctx); !errors.Is(err, errs.ErrNothingToUndo) {
+		t.Errorf("expected ErrNothingToUndo, got %v", err)
+	}
+
+	itemID := copyItem(t, b, "hello", bore.SetClipboardOptions{})
+
+	result, err := b.Undo(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if result.Description != "copy item "+itemID {
+		t.Errorf("expected the copy to be undone, got %q", result.Description)
+	}
+
+	if item := findItem(t, b, itemID); item != nil {
+		t.Errorf("expected the copied item to be gone, got %+v", item)
+	}
+
+	if _, err := b.Redo(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if item := findItem(t, b, itemID); item == nil || string(item.Content) != "hello" {
+		t.Errorf("expected the item to be back with the same ID, got %+v", item)
+	}
+
+	if _, err := b.Redo(ctx); !errors.Is(err, errs.ErrNothingToRedo) {
+		t.Errorf("expected ErrNothingToRedo, got %v", err)
+	}
+}
+
+func Test_UndoDeleteAndPin(t *testing.T) {
+	ctx := context.Background()
+	b := newBore(t)
+	itemID := copyItem(t, b, "hello", bore.SetClipboardOptions{})
+
+	if err := b.Clipboard().Pin(ctx, itemID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := b.Clipboard().Delete(ctx, itemID, bore.DeleteItemOptions{Force: true}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if _, err := b.Undo(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if item := findItem(t, b, itemID); item == nil || !item.IsPinned() {
+		t.Errorf("expected the item to be restored with its pin, got %+v", item)
+	}
+
+	if _, err := b.Undo(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if item := findItem(t, b, itemID); item == nil || item.IsPinned() {
+		t.Errorf("expected the pin to be undone, got %+v", item)
+	}
+}
+
+func Test_UndoEdit(t *testing.T) {
+	ctx := context.Background()
+	b := newBore(t)
+	itemID := copyItem(t, b, "draft", bore.SetClipboardOptions{})
+
+	if _, err := b.Clipboard().Update(ctx, itemID, []byte("final"), bore.UpdateItemOptions{ExpectedVersion: -1}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if _, err := b.Undo(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if item := findItem(t, b, itemID); item == nil || string(item.Content) != "draft" {
+		t.Errorf("expected the edit to be undone, got %+v", item)
+	}
+
+	if _, err := b.Redo(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if item := findItem(t, b, itemID); item == nil || string(item.Content) != "final" {
+		t.Errorf("expected the edit to be redone, got %+v", item)
+	}
+}
+
+// A new operation after an undo starts a new branch of history, the undone operation can not be redone anymore.
+func Test_NewOperationClearsRedo(t *testing.T) {
+	ctx := context.Background()
+	b := newBore(t)
+	copyItem(t, b, "first", bore.SetClipboardOptions{})
+
+	if _, err := b.Undo(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	copyItem(t, b, "second", bore.SetClipboardOptions{})
+
+	if _, err := b.Redo(ctx); !errors.Is(err, errs.ErrNothingToRedo) {
+		t.Errorf("expected ErrNothingToRedo, got %v", err)
+	}
+}